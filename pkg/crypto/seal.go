@@ -0,0 +1,277 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"dario.lol/gotils/pkg/encoding"
+	"dario.lol/gotils/pkg/hash"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+type Algorithm string
+
+const (
+	AES256GCM         Algorithm = "aes-256-gcm"
+	XChaCha20Poly1305 Algorithm = "xchacha20-poly1305"
+)
+
+// KeySize is the key length in bytes of every supported algorithm
+const KeySize = 32
+
+const envelopeVersion = 1
+
+var (
+	ErrInvalidKeySize       = errors.New("invalid key size: must be 32 bytes")
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+	ErrUnsupportedVersion   = errors.New("unsupported envelope version")
+	ErrMalformedEnvelope    = errors.New("malformed envelope")
+	ErrDecryptionFailed     = errors.New("decryption failed: wrong key or tampered envelope")
+	ErrPassphraseRequired   = errors.New("envelope is passphrase protected")
+	ErrKeyRequired          = errors.New("envelope is not passphrase protected")
+	ErrKDFLimitExceeded     = errors.New("key derivation parameters exceed limit")
+)
+
+// MaxPassphraseParams bounds the Argon2id cost OpenWithPassphrase accepts from
+// an envelope, four times hash.Argon2idDefaultParams. Without a bound a crafted
+// envelope could make the opener allocate gigabytes or spin indefinitely.
+var MaxPassphraseParams = hash.Argon2idParams{
+	Memory:      4 * hash.Argon2idDefaultParams.Memory,
+	Iterations:  4 * hash.Argon2idDefaultParams.Iterations,
+	Parallelism: 4 * hash.Argon2idDefaultParams.Parallelism,
+}
+
+// Envelope is a self-describing sealed message. Its string form is
+// v<version>.<algorithm>.<kdf>.<nonce>.<ciphertext> where kdf, nonce and
+// ciphertext are unpadded base64url and kdf is empty unless the key was
+// derived from a passphrase. Everything before the nonce is authenticated as
+// additional data exactly as written, so the header cannot be altered or
+// re-encoded without Open failing.
+type Envelope struct {
+	Version    int
+	Algorithm  Algorithm
+	KDF        *hash.KeyDerivation
+	Nonce      []byte
+	Ciphertext []byte
+
+	// rawHeader is the header as parsed, authenticated instead of header()
+	rawHeader string
+}
+
+// ParseEnvelope parses an envelope created by Seal or SealWithPassphrase
+func ParseEnvelope(s string) (Envelope, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 5 {
+		return Envelope{}, fmt.Errorf("%w: expected 5 parts, got %d", ErrMalformedEnvelope, len(parts))
+	}
+
+	version, err := strconv.Atoi(strings.TrimPrefix(parts[0], "v"))
+	if err != nil || !strings.HasPrefix(parts[0], "v") {
+		return Envelope{}, fmt.Errorf("%w: version %q", ErrMalformedEnvelope, parts[0])
+	}
+	if version != envelopeVersion {
+		return Envelope{}, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	e := Envelope{Version: version, Algorithm: Algorithm(parts[1]), rawHeader: strings.Join(parts[:3], ".")}
+	if parts[2] != "" {
+		encoded, err := encoding.B64URLRawDecode(parts[2])
+		if err != nil {
+			return Envelope{}, fmt.Errorf("%w: kdf: %v", ErrMalformedEnvelope, err)
+		}
+		kdf, err := hash.ParseKeyDerivation(encoded)
+		if err != nil {
+			return Envelope{}, fmt.Errorf("%w: kdf: %v", ErrMalformedEnvelope, err)
+		}
+		e.KDF = &kdf
+	}
+
+	e.Nonce, err = encoding.B64URLRawDecodeToBytes(parts[3])
+	if err != nil {
+		return Envelope{}, fmt.Errorf("%w: nonce: %v", ErrMalformedEnvelope, err)
+	}
+	e.Ciphertext, err = encoding.B64URLRawDecodeToBytes(parts[4])
+	if err != nil {
+		return Envelope{}, fmt.Errorf("%w: ciphertext: %v", ErrMalformedEnvelope, err)
+	}
+	return e, nil
+}
+
+// String formats the envelope
+func (e Envelope) String() string {
+	return e.header() + "." + encoding.B64URLRawEncodeBytes(e.Nonce) + "." + encoding.B64URLRawEncodeBytes(e.Ciphertext)
+}
+
+func (e Envelope) header() string {
+	var kdf string
+	if e.KDF != nil {
+		kdf = encoding.B64URLRawEncode(e.KDF.String())
+	}
+	return "v" + strconv.Itoa(e.Version) + "." + string(e.Algorithm) + "." + kdf
+}
+
+// GenerateKey returns a random key for Seal
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Seal encrypts and authenticates plaintext with a 32 byte key
+func Seal(alg Algorithm, key, plaintext []byte) (string, error) {
+	e, err := seal(Envelope{Version: envelopeVersion, Algorithm: alg}, key, plaintext)
+	if err != nil {
+		return "", err
+	}
+	return e.String(), nil
+}
+
+// SealString is like Seal for string plaintexts
+func SealString(alg Algorithm, key []byte, plaintext string) (string, error) {
+	return Seal(alg, key, []byte(plaintext))
+}
+
+// Open decrypts an envelope created by Seal
+func Open(key []byte, envelope string) ([]byte, error) {
+	e, err := ParseEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+	if e.KDF != nil {
+		return nil, ErrPassphraseRequired
+	}
+	return open(e, key)
+}
+
+// OpenString is like Open for string plaintexts
+func OpenString(key []byte, envelope string) (string, error) {
+	plaintext, err := Open(key, envelope)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// SealWithPassphrase encrypts plaintext with a key derived from passphrase using Argon2id with hash.Argon2idDefaultParams
+func SealWithPassphrase(alg Algorithm, passphrase, plaintext []byte) (string, error) {
+	return SealWithPassphraseParams(alg, passphrase, plaintext, hash.Argon2idDefaultParams)
+}
+
+// SealWithPassphraseParams encrypts plaintext with a key derived from passphrase using Argon2id with p.
+// The salt and p are stored in the envelope.
+func SealWithPassphraseParams(alg Algorithm, passphrase, plaintext []byte, p hash.Argon2idParams) (string, error) {
+	kdf, err := hash.NewArgon2idKeyDerivation(p)
+	if err != nil {
+		return "", err
+	}
+	key, err := kdf.DeriveKey(passphrase, KeySize)
+	if err != nil {
+		return "", err
+	}
+	e, err := seal(Envelope{Version: envelopeVersion, Algorithm: alg, KDF: &kdf}, key, plaintext)
+	if err != nil {
+		return "", err
+	}
+	return e.String(), nil
+}
+
+// SealStringWithPassphrase is like SealWithPassphrase for strings
+func SealStringWithPassphrase(alg Algorithm, passphrase, plaintext string) (string, error) {
+	return SealWithPassphrase(alg, []byte(passphrase), []byte(plaintext))
+}
+
+// OpenWithPassphrase decrypts an envelope created by SealWithPassphrase,
+// rejecting key derivations more expensive than MaxPassphraseParams
+func OpenWithPassphrase(passphrase []byte, envelope string) ([]byte, error) {
+	return OpenWithPassphraseParams(passphrase, envelope, MaxPassphraseParams)
+}
+
+// OpenWithPassphraseParams is like OpenWithPassphrase but rejects key
+// derivations whose memory, iterations or parallelism exceed max. Use it to
+// open envelopes sealed with params above MaxPassphraseParams.
+func OpenWithPassphraseParams(passphrase []byte, envelope string, max hash.Argon2idParams) ([]byte, error) {
+	e, err := ParseEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+	if e.KDF == nil {
+		return nil, ErrKeyRequired
+	}
+	if e.KDF.Algorithm != hash.KDFArgon2id {
+		return nil, fmt.Errorf("%w: kdf %s", ErrUnsupportedAlgorithm, e.KDF.Algorithm)
+	}
+	if p := e.KDF.Argon2id; p.Memory > max.Memory || p.Iterations > max.Iterations || p.Parallelism > max.Parallelism {
+		return nil, fmt.Errorf("%w: m=%d,t=%d,p=%d", ErrKDFLimitExceeded, p.Memory, p.Iterations, p.Parallelism)
+	}
+	key, err := e.KDF.DeriveKey(passphrase, KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return open(e, key)
+}
+
+// OpenStringWithPassphrase is like OpenWithPassphrase for strings
+func OpenStringWithPassphrase(passphrase, envelope string) (string, error) {
+	plaintext, err := OpenWithPassphrase([]byte(passphrase), envelope)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func newAEAD(alg Algorithm, key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKeySize
+	}
+	switch alg {
+	case AES256GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case XChaCha20Poly1305:
+		return chacha20poly1305.NewX(key)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, alg)
+	}
+}
+
+func seal(e Envelope, key, plaintext []byte) (Envelope, error) {
+	aead, err := newAEAD(e.Algorithm, key)
+	if err != nil {
+		return Envelope{}, err
+	}
+	e.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(e.Nonce); err != nil {
+		return Envelope{}, err
+	}
+	e.Ciphertext = aead.Seal(nil, e.Nonce, plaintext, []byte(e.header()))
+	return e, nil
+}
+
+func open(e Envelope, key []byte) ([]byte, error) {
+	aead, err := newAEAD(e.Algorithm, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: nonce must be %d bytes", ErrMalformedEnvelope, aead.NonceSize())
+	}
+	header := e.rawHeader
+	if header == "" {
+		header = e.header()
+	}
+	plaintext, err := aead.Open(nil, e.Nonce, e.Ciphertext, []byte(header))
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,177 @@
+package crypto
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"dario.lol/gotils/pkg/hash"
+	"github.com/matryer/is"
+)
+
+var testParams = hash.Argon2idParams{
+	Memory:      64,
+	Iterations:  1,
+	Parallelism: 1,
+	KeyLen:      32,
+}
+
+func TestSealAndOpen(t *testing.T) {
+	for _, alg := range []Algorithm{AES256GCM, XChaCha20Poly1305} {
+		t.Run(string(alg), func(t *testing.T) {
+			is := is.New(t)
+			key, err := GenerateKey()
+			is.NoErr(err)
+
+			envelope, err := SealString(alg, key, "database password")
+			is.NoErr(err)
+			is.True(strings.HasPrefix(envelope, "v1."+string(alg)+".."))
+
+			plaintext, err := OpenString(key, envelope)
+			is.NoErr(err)
+			is.Equal(plaintext, "database password")
+
+			again, err := SealString(alg, key, "database password")
+			is.NoErr(err)
+			is.True(again != envelope)
+		})
+	}
+}
+
+func TestOpenWrongKey(t *testing.T) {
+	i := is.New(t)
+	key, err := GenerateKey()
+	i.NoErr(err)
+	other, err := GenerateKey()
+	i.NoErr(err)
+
+	envelope, err := SealString(XChaCha20Poly1305, key, "secret")
+	i.NoErr(err)
+
+	_, err = Open(other, envelope)
+	i.True(errors.Is(err, ErrDecryptionFailed))
+}
+
+func TestOpenTamperedHeader(t *testing.T) {
+	i := is.New(t)
+	key, err := GenerateKey()
+	i.NoErr(err)
+
+	envelope, err := SealString(AES256GCM, key, "secret")
+	i.NoErr(err)
+
+	e, err := ParseEnvelope(envelope)
+	i.NoErr(err)
+	e.Ciphertext[0] ^= 1
+	_, err = Open(key, e.String())
+	i.True(errors.Is(err, ErrDecryptionFailed))
+
+	_, err = Open(key, strings.Replace(envelope, "v1.", "v2.", 1))
+	i.True(errors.Is(err, ErrUnsupportedVersion))
+
+	// non-canonical headers parse to the same values but are not what was sealed
+	_, err = Open(key, strings.Replace(envelope, "v1.", "v01.", 1))
+	i.True(errors.Is(err, ErrDecryptionFailed))
+}
+
+func TestSealInvalidInput(t *testing.T) {
+	i := is.New(t)
+
+	_, err := Seal(AES256GCM, []byte("short"), []byte("secret"))
+	i.True(errors.Is(err, ErrInvalidKeySize))
+
+	key, err := GenerateKey()
+	i.NoErr(err)
+	_, err = Seal("rot13", key, []byte("secret"))
+	i.True(errors.Is(err, ErrUnsupportedAlgorithm))
+}
+
+func TestSealWithPassphrase(t *testing.T) {
+	for _, alg := range []Algorithm{AES256GCM, XChaCha20Poly1305} {
+		t.Run(string(alg), func(t *testing.T) {
+			is := is.New(t)
+			envelope, err := SealWithPassphraseParams(alg, []byte("correct horse"), []byte("api token"), testParams)
+			is.NoErr(err)
+
+			e, err := ParseEnvelope(envelope)
+			is.NoErr(err)
+			is.True(e.KDF != nil)
+			is.Equal(e.KDF.Algorithm, hash.KDFArgon2id)
+			is.Equal(e.KDF.Argon2id.Memory, testParams.Memory)
+
+			plaintext, err := OpenStringWithPassphrase("correct horse", envelope)
+			is.NoErr(err)
+			is.Equal(plaintext, "api token")
+
+			_, err = OpenStringWithPassphrase("wrong horse", envelope)
+			is.True(errors.Is(err, ErrDecryptionFailed))
+
+			_, err = Open(make([]byte, KeySize), envelope)
+			is.True(errors.Is(err, ErrPassphraseRequired))
+		})
+	}
+}
+
+func TestOpenWithPassphraseLimits(t *testing.T) {
+	i := is.New(t)
+	expensive := hash.Argon2idParams{Memory: 1 << 20, Iterations: 1000, Parallelism: 1, KeyLen: 32}
+	kdf, err := hash.NewArgon2idKeyDerivation(expensive)
+	i.NoErr(err)
+	e := Envelope{Version: envelopeVersion, Algorithm: AES256GCM, KDF: &kdf, Nonce: make([]byte, 12), Ciphertext: make([]byte, 16)}
+
+	_, err = OpenWithPassphrase([]byte("passphrase"), e.String())
+	i.True(errors.Is(err, ErrKDFLimitExceeded))
+
+	// params no stored hash may use are rejected while parsing
+	e.KDF.Argon2id = hash.Argon2idParams{Memory: 4194304, Iterations: 1<<32 - 1, Parallelism: 1}
+	_, err = OpenWithPassphrase([]byte("passphrase"), e.String())
+	i.True(errors.Is(err, ErrMalformedEnvelope))
+
+	envelope, err := SealWithPassphraseParams(AES256GCM, []byte("passphrase"), []byte("secret"), testParams)
+	i.NoErr(err)
+	_, err = OpenWithPassphraseParams([]byte("passphrase"), envelope, hash.Argon2idParams{Memory: 32, Iterations: 1, Parallelism: 1})
+	i.True(errors.Is(err, ErrKDFLimitExceeded))
+	plaintext, err := OpenWithPassphraseParams([]byte("passphrase"), envelope, testParams)
+	i.NoErr(err)
+	i.Equal(string(plaintext), "secret")
+
+	scryptKDF, err := hash.NewScryptKeyDerivation(hash.ScryptParams{LogN: 4, R: 8, P: 1})
+	i.NoErr(err)
+	e.KDF = &scryptKDF
+	_, err = OpenWithPassphrase([]byte("passphrase"), e.String())
+	i.True(errors.Is(err, ErrUnsupportedAlgorithm))
+}
+
+func TestOpenWithPassphraseRequiresKDF(t *testing.T) {
+	i := is.New(t)
+	key, err := GenerateKey()
+	i.NoErr(err)
+	envelope, err := SealString(AES256GCM, key, "secret")
+	i.NoErr(err)
+
+	_, err = OpenStringWithPassphrase("passphrase", envelope)
+	i.True(errors.Is(err, ErrKeyRequired))
+}
+
+func TestParseEnvelopeErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		envelope string
+		expected error
+	}{
+		{name: "parts", envelope: "v1.aes-256-gcm", expected: ErrMalformedEnvelope},
+		{name: "version format", envelope: "1.aes-256-gcm..AAAA.AAAA", expected: ErrMalformedEnvelope},
+		{name: "version", envelope: "v9.aes-256-gcm..AAAA.AAAA", expected: ErrUnsupportedVersion},
+		{name: "kdf", envelope: "v1.aes-256-gcm.JGJvZ3Vz.AAAA.AAAA", expected: ErrMalformedEnvelope},
+		{name: "nonce", envelope: "v1.aes-256-gcm..!!!!.AAAA", expected: ErrMalformedEnvelope},
+		{name: "ciphertext", envelope: "v1.aes-256-gcm..AAAA.!!!!", expected: ErrMalformedEnvelope},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			_, err := ParseEnvelope(tc.envelope)
+			is.True(errors.Is(err, tc.expected))
+		})
+	}
+}
@@ -1,258 +1,340 @@
 package encoding
 
-import "encoding/base64"
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	// StdAlphabet is the standard base64 alphabet of RFC 4648
+	StdAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	// URLAlphabet is the URL and filename safe base64 alphabet of RFC 4648
+	URLAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+
+	// StdPadding is the default padding character
+	StdPadding rune = '='
+	// NoPadding disables padding
+	NoPadding rune = -1
+)
+
+var ErrInvalidAlphabet = errors.New("invalid base64 alphabet: must be 64 unique bytes without padding, CR or LF")
+
+var (
+	StdEncoding    = Encoding{enc: base64.StdEncoding, padding: StdPadding}
+	URLEncoding    = Encoding{enc: base64.URLEncoding, padding: StdPadding}
+	RawStdEncoding = Encoding{enc: base64.RawStdEncoding, padding: NoPadding}
+	RawURLEncoding = Encoding{enc: base64.RawURLEncoding, padding: NoPadding}
+)
+
+// Encoding is a base64 variant defined by its alphabet and padding. Encoded
+// data is always decoded with the same padding it was encoded with. The zero
+// value is StdEncoding.
+type Encoding struct {
+	enc     *base64.Encoding
+	padding rune
+}
+
+// NewEncoding creates an encoding with a custom alphabet of 64 unique bytes,
+// padded with padding (default: StdPadding, NoPadding disables it)
+func NewEncoding(alphabet string, padding ...rune) (Encoding, error) {
+	p := StdPadding
+	if len(padding) > 0 {
+		p = padding[0]
+	}
+	if len(alphabet) != 64 || strings.ContainsAny(alphabet, "\r\n") ||
+		(p != NoPadding && strings.ContainsRune(alphabet, p)) || !validPadding(p) {
+		return Encoding{}, ErrInvalidAlphabet
+	}
+	for i := range len(alphabet) {
+		if strings.IndexByte(alphabet[i+1:], alphabet[i]) >= 0 {
+			return Encoding{}, ErrInvalidAlphabet
+		}
+	}
+	return Encoding{enc: base64.NewEncoding(alphabet).WithPadding(p), padding: p}, nil
+}
+
+// MustNewEncoding is a helper that wraps NewEncoding and panics if an error occurs
+func MustNewEncoding(alphabet string, padding ...rune) Encoding {
+	e, err := NewEncoding(alphabet, padding...)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+func validPadding(p rune) bool {
+	return p == NoPadding || (p >= 0 && p <= 0xff && p != '\r' && p != '\n')
+}
+
+// WithPadding returns a copy of e padded with padding, NoPadding disables it.
+// Like encoding/base64 it panics if padding is part of the alphabet, CR, LF or above 0xff.
+func (e Encoding) WithPadding(padding rune) Encoding {
+	return Encoding{enc: e.base().WithPadding(padding), padding: padding}
+}
+
+// Padding returns the padding character, or NoPadding
+func (e Encoding) Padding() rune {
+	if e.enc == nil {
+		return StdPadding
+	}
+	return e.padding
+}
+
+func (e Encoding) base() *base64.Encoding {
+	if e.enc == nil {
+		return base64.StdEncoding
+	}
+	return e.enc
+}
+
+// Encode returns the encoding of src
+func (e Encoding) Encode(src []byte) []byte {
+	enc := e.base()
+	buf := make([]byte, enc.EncodedLen(len(src)))
+	enc.Encode(buf, src)
+	return buf
+}
+
+// EncodeToString returns the encoding of src as string
+func (e Encoding) EncodeToString(src []byte) string {
+	return e.base().EncodeToString(src)
+}
+
+// Decode returns the bytes represented by src
+func (e Encoding) Decode(src []byte) ([]byte, error) {
+	enc := e.base()
+	buf := make([]byte, enc.DecodedLen(len(src)))
+	n, err := enc.Decode(buf, src)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+// DecodeString returns the bytes represented by s
+func (e Encoding) DecodeString(s string) ([]byte, error) {
+	return e.base().DecodeString(s)
+}
+
+// EncodedLen returns the length of the encoding of n bytes
+func (e Encoding) EncodedLen(n int) int {
+	return e.base().EncodedLen(n)
+}
+
+// DecodedLen returns the maximum length of the data decoded from n bytes
+func (e Encoding) DecodedLen(n int) int {
+	return e.base().DecodedLen(n)
+}
+
+// NewEncoder returns a stream encoder writing to w. Close it to flush the final
+// partial block and its padding.
+func (e Encoding) NewEncoder(w io.Writer) io.WriteCloser {
+	return base64.NewEncoder(e.base(), w)
+}
+
+// NewDecoder returns a stream decoder reading from r. Line breaks are ignored.
+func (e Encoding) NewDecoder(r io.Reader) io.Reader {
+	return base64.NewDecoder(e.base(), r)
+}
 
 func B64Encode(data string, padding ...rune) string {
-	enc := maybeApplyPadding(base64.StdEncoding, padding...)
-	return enc.EncodeToString([]byte(data))
+	return withPadding(StdEncoding, padding).EncodeToString([]byte(data))
 }
 
 func B64URLEncode(data string, padding ...rune) string {
-	enc := maybeApplyPadding(base64.URLEncoding, padding...)
-	return enc.EncodeToString([]byte(data))
+	return withPadding(URLEncoding, padding).EncodeToString([]byte(data))
 }
 
 func B64RawEncode(data string, padding ...rune) string {
-	enc := maybeApplyPadding(base64.RawStdEncoding, padding...)
-	return enc.EncodeToString([]byte(data))
+	return withPadding(RawStdEncoding, padding).EncodeToString([]byte(data))
 }
 
 func B64URLRawEncode(data string, padding ...rune) string {
-	enc := maybeApplyPadding(base64.RawURLEncoding, padding...)
-	return enc.EncodeToString([]byte(data))
+	return withPadding(RawURLEncoding, padding).EncodeToString([]byte(data))
 }
 
 func B64EncodeBytes(data []byte, padding ...rune) string {
-	enc := maybeApplyPadding(base64.StdEncoding, padding...)
-	return enc.EncodeToString(data)
+	return withPadding(StdEncoding, padding).EncodeToString(data)
 }
 
 func B64URLEncodeBytes(data []byte, padding ...rune) string {
-	enc := maybeApplyPadding(base64.URLEncoding, padding...)
-	return enc.EncodeToString(data)
+	return withPadding(URLEncoding, padding).EncodeToString(data)
 }
 
 func B64RawEncodeBytes(data []byte, padding ...rune) string {
-	enc := maybeApplyPadding(base64.RawStdEncoding, padding...)
-	return enc.EncodeToString(data)
+	return withPadding(RawStdEncoding, padding).EncodeToString(data)
 }
 
 func B64URLRawEncodeBytes(data []byte, padding ...rune) string {
-	enc := maybeApplyPadding(base64.RawURLEncoding, padding...)
-	return enc.EncodeToString(data)
+	return withPadding(RawURLEncoding, padding).EncodeToString(data)
 }
 
 func B64EncodeToBytes(data string, padding ...rune) []byte {
-	enc := maybeApplyPadding(base64.StdEncoding, padding...)
-	buf := make([]byte, enc.EncodedLen(len(data)))
-	enc.Encode(buf, []byte(data))
-	return buf
+	return withPadding(StdEncoding, padding).Encode([]byte(data))
 }
 
 func B64URLEncodeToBytes(data string, padding ...rune) []byte {
-	enc := maybeApplyPadding(base64.URLEncoding, padding...)
-	buf := make([]byte, enc.EncodedLen(len(data)))
-	enc.Encode(buf, []byte(data))
-	return buf
+	return withPadding(URLEncoding, padding).Encode([]byte(data))
 }
 
 func B64RawEncodeToBytes(data string, padding ...rune) []byte {
-	enc := maybeApplyPadding(base64.RawStdEncoding, padding...)
-	buf := make([]byte, enc.EncodedLen(len(data)))
-	enc.Encode(buf, []byte(data))
-	return buf
+	return withPadding(RawStdEncoding, padding).Encode([]byte(data))
 }
 
 func B64URLRawEncodeToBytes(data string, padding ...rune) []byte {
-	enc := maybeApplyPadding(base64.RawURLEncoding, padding...)
-	buf := make([]byte, enc.EncodedLen(len(data)))
-	enc.Encode(buf, []byte(data))
-	return buf
+	return withPadding(RawURLEncoding, padding).Encode([]byte(data))
 }
 
 func B64EncodeBytesToBytes(data []byte, padding ...rune) []byte {
-	enc := maybeApplyPadding(base64.StdEncoding, padding...)
-	buf := make([]byte, enc.EncodedLen(len(data)))
-	enc.Encode(buf, data)
-	return buf
+	return withPadding(StdEncoding, padding).Encode(data)
 }
 
 func B64URLEncodeBytesToBytes(data []byte, padding ...rune) []byte {
-	enc := maybeApplyPadding(base64.URLEncoding, padding...)
-	buf := make([]byte, enc.EncodedLen(len(data)))
-	enc.Encode(buf, data)
-	return buf
+	return withPadding(URLEncoding, padding).Encode(data)
 }
 
 func B64RawEncodeBytesToBytes(data []byte, padding ...rune) []byte {
-	enc := maybeApplyPadding(base64.RawStdEncoding, padding...)
-	buf := make([]byte, enc.EncodedLen(len(data)))
-	enc.Encode(buf, data)
-	return buf
+	return withPadding(RawStdEncoding, padding).Encode(data)
 }
 
 func B64URLRawEncodeBytesToBytes(data []byte, padding ...rune) []byte {
-	enc := maybeApplyPadding(base64.RawURLEncoding, padding...)
-	buf := make([]byte, enc.EncodedLen(len(data)))
-	enc.Encode(buf, data)
-	return buf
+	return withPadding(RawURLEncoding, padding).Encode(data)
 }
 
-func B64Decode(data string) (string, error) {
-	b, err := base64.StdEncoding.DecodeString(data)
+func B64Decode(data string, padding ...rune) (string, error) {
+	b, err := withPadding(StdEncoding, padding).DecodeString(data)
 	if err != nil {
 		return "", err
 	}
 	return string(b), nil
 }
 
-func B64URLDecode(data string) (string, error) {
-	b, err := base64.URLEncoding.DecodeString(data)
+func B64URLDecode(data string, padding ...rune) (string, error) {
+	b, err := withPadding(URLEncoding, padding).DecodeString(data)
 	if err != nil {
 		return "", err
 	}
 	return string(b), nil
 }
 
-func B64RawDecode(data string) (string, error) {
-	b, err := base64.RawStdEncoding.DecodeString(data)
+func B64RawDecode(data string, padding ...rune) (string, error) {
+	b, err := withPadding(RawStdEncoding, padding).DecodeString(data)
 	if err != nil {
 		return "", err
 	}
 	return string(b), nil
 }
 
-func B64URLRawDecode(data string) (string, error) {
-	b, err := base64.RawURLEncoding.DecodeString(data)
+func B64URLRawDecode(data string, padding ...rune) (string, error) {
+	b, err := withPadding(RawURLEncoding, padding).DecodeString(data)
 	if err != nil {
 		return "", err
 	}
 	return string(b), nil
 }
 
-func B64DecodeToBytes(data string) ([]byte, error) {
-	return base64.StdEncoding.DecodeString(data)
+func B64DecodeToBytes(data string, padding ...rune) ([]byte, error) {
+	return withPadding(StdEncoding, padding).DecodeString(data)
 }
 
-func B64URLDecodeToBytes(data string) ([]byte, error) {
-	return base64.URLEncoding.DecodeString(data)
+func B64URLDecodeToBytes(data string, padding ...rune) ([]byte, error) {
+	return withPadding(URLEncoding, padding).DecodeString(data)
 }
 
-func B64RawDecodeToBytes(data string) ([]byte, error) {
-	return base64.RawStdEncoding.DecodeString(data)
+func B64RawDecodeToBytes(data string, padding ...rune) ([]byte, error) {
+	return withPadding(RawStdEncoding, padding).DecodeString(data)
 }
 
-func B64URLRawDecodeToBytes(data string) ([]byte, error) {
-	return base64.RawURLEncoding.DecodeString(data)
+func B64URLRawDecodeToBytes(data string, padding ...rune) ([]byte, error) {
+	return withPadding(RawURLEncoding, padding).DecodeString(data)
 }
 
-func B64DecodeBytes(data []byte) (string, error) {
-	buf := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
-	n, err := base64.StdEncoding.Decode(buf, data)
+func B64DecodeBytes(data []byte, padding ...rune) (string, error) {
+	b, err := withPadding(StdEncoding, padding).Decode(data)
 	if err != nil {
 		return "", err
 	}
-	return string(buf[:n]), nil
+	return string(b), nil
 }
 
-func B64URLDecodeBytes(data []byte) (string, error) {
-	buf := make([]byte, base64.URLEncoding.DecodedLen(len(data)))
-	n, err := base64.URLEncoding.Decode(buf, data)
+func B64URLDecodeBytes(data []byte, padding ...rune) (string, error) {
+	b, err := withPadding(URLEncoding, padding).Decode(data)
 	if err != nil {
 		return "", err
 	}
-	return string(buf[:n]), nil
+	return string(b), nil
 }
 
-func B64RawDecodeBytes(data []byte) (string, error) {
-	buf := make([]byte, base64.RawStdEncoding.DecodedLen(len(data)))
-	n, err := base64.RawStdEncoding.Decode(buf, data)
+func B64RawDecodeBytes(data []byte, padding ...rune) (string, error) {
+	b, err := withPadding(RawStdEncoding, padding).Decode(data)
 	if err != nil {
 		return "", err
 	}
-	return string(buf[:n]), nil
+	return string(b), nil
 }
 
-func B64URLRawDecodeBytes(data []byte) (string, error) {
-	buf := make([]byte, base64.RawURLEncoding.DecodedLen(len(data)))
-	n, err := base64.RawURLEncoding.Decode(buf, data)
+func B64URLRawDecodeBytes(data []byte, padding ...rune) (string, error) {
+	b, err := withPadding(RawURLEncoding, padding).Decode(data)
 	if err != nil {
 		return "", err
 	}
-	return string(buf[:n]), nil
+	return string(b), nil
 }
 
-func B64DecodeBytesToBytes(data []byte) ([]byte, error) {
-	buf := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
-	n, err := base64.StdEncoding.Decode(buf, data)
-	if err != nil {
-		return nil, err
-	}
-	return buf[:n], nil
+func B64DecodeBytesToBytes(data []byte, padding ...rune) ([]byte, error) {
+	return withPadding(StdEncoding, padding).Decode(data)
 }
 
-func B64URLDecodeBytesToBytes(data []byte) ([]byte, error) {
-	buf := make([]byte, base64.URLEncoding.DecodedLen(len(data)))
-	n, err := base64.URLEncoding.Decode(buf, data)
-	if err != nil {
-		return nil, err
-	}
-	return buf[:n], nil
+func B64URLDecodeBytesToBytes(data []byte, padding ...rune) ([]byte, error) {
+	return withPadding(URLEncoding, padding).Decode(data)
 }
 
-func B64RawDecodeBytesToBytes(data []byte) ([]byte, error) {
-	buf := make([]byte, base64.RawStdEncoding.DecodedLen(len(data)))
-	n, err := base64.RawStdEncoding.Decode(buf, data)
-	if err != nil {
-		return nil, err
-	}
-	return buf[:n], nil
+func B64RawDecodeBytesToBytes(data []byte, padding ...rune) ([]byte, error) {
+	return withPadding(RawStdEncoding, padding).Decode(data)
 }
 
-func B64URLRawDecodeBytesToBytes(data []byte) ([]byte, error) {
-	buf := make([]byte, base64.RawURLEncoding.DecodedLen(len(data)))
-	n, err := base64.RawURLEncoding.Decode(buf, data)
-	if err != nil {
-		return nil, err
-	}
-	return buf[:n], nil
+func B64URLRawDecodeBytesToBytes(data []byte, padding ...rune) ([]byte, error) {
+	return withPadding(RawURLEncoding, padding).Decode(data)
 }
 
-func MustB64Decode(data string) string {
-	result, err := B64Decode(data)
+func MustB64Decode(data string, padding ...rune) string {
+	result, err := B64Decode(data, padding...)
 	if err != nil {
 		panic(err)
 	}
 	return result
 }
 
-func MustB64URLDecode(data string) string {
-	result, err := B64URLDecode(data)
+func MustB64URLDecode(data string, padding ...rune) string {
+	result, err := B64URLDecode(data, padding...)
 	if err != nil {
 		panic(err)
 	}
 	return result
 }
 
-func MustB64RawDecode(data string) string {
-	result, err := B64RawDecode(data)
+func MustB64RawDecode(data string, padding ...rune) string {
+	result, err := B64RawDecode(data, padding...)
 	if err != nil {
 		panic(err)
 	}
 	return result
 }
 
-func MustB64URLRawDecode(data string) string {
-	result, err := B64URLRawDecode(data)
+func MustB64URLRawDecode(data string, padding ...rune) string {
+	result, err := B64URLRawDecode(data, padding...)
 	if err != nil {
 		panic(err)
 	}
 	return result
 }
 
-func maybeApplyPadding(enc *base64.Encoding, padding ...rune) *base64.Encoding {
+// withPadding applies the optional padding argument of the B64 functions
+func withPadding(e Encoding, padding []rune) Encoding {
 	if len(padding) > 0 {
-		return enc.WithPadding(padding[0])
+		return e.WithPadding(padding[0])
 	}
-	return enc
+	return e
 }
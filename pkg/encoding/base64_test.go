@@ -1,6 +1,12 @@
 package encoding
 
 import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/matryer/is"
@@ -46,3 +52,119 @@ func TestB64MustDecodeWithInvalidInput(t *testing.T) {
 
 	MustB64Decode("invalid-base64")
 }
+
+func TestB64DecodeWithCustomPadding(t *testing.T) {
+	i := is.New(t)
+	original := "Hello, World!!"
+
+	encoded := B64Encode(original, '*')
+	i.Equal(encoded, "SGVsbG8sIFdvcmxkISE*")
+	decoded, err := B64Decode(encoded, '*')
+	i.NoErr(err)
+	i.Equal(decoded, original)
+
+	_, err = B64Decode(encoded)
+	i.True(err != nil)
+
+	b, err := B64URLRawDecodeBytesToBytes(B64URLRawEncodeBytesToBytes([]byte(original), '.'), '.')
+	i.NoErr(err)
+	i.Equal(string(b), original)
+}
+
+func TestEncodingVariants(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0xfe, 'a'}
+	tests := []struct {
+		name string
+		enc  Encoding
+		want string
+	}{
+		{"std", StdEncoding, "+//+YQ=="},
+		{"url", URLEncoding, "-__-YQ=="},
+		{"raw std", RawStdEncoding, "+//+YQ"},
+		{"raw url", RawURLEncoding, "-__-YQ"},
+		{"custom padding", URLEncoding.WithPadding('~'), "-__-YQ~~"},
+		{"padded raw", RawStdEncoding.WithPadding(StdPadding), "+//+YQ=="},
+		{"zero value", Encoding{}, "+//+YQ=="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(tt.enc.EncodeToString(data), tt.want)
+			is.Equal(string(tt.enc.Encode(data)), tt.want)
+			is.Equal(tt.enc.EncodedLen(len(data)), len(tt.want))
+
+			decoded, err := tt.enc.DecodeString(tt.want)
+			is.NoErr(err)
+			is.Equal(decoded, data)
+			decoded, err = tt.enc.Decode([]byte(tt.want))
+			is.NoErr(err)
+			is.Equal(decoded, data)
+		})
+	}
+}
+
+func TestEncodingPadding(t *testing.T) {
+	i := is.New(t)
+	i.Equal(StdEncoding.Padding(), StdPadding)
+	i.Equal(RawURLEncoding.Padding(), NoPadding)
+	i.Equal(Encoding{}.Padding(), StdPadding)
+	i.Equal(StdEncoding.WithPadding('*').Padding(), '*')
+	i.Equal(StdEncoding.WithPadding(NoPadding).EncodeToString([]byte("a")), "YQ")
+}
+
+func TestNewEncoding(t *testing.T) {
+	i := is.New(t)
+	// reversed standard alphabet
+	alphabet := "/+9876543210zyxwvutsrqponmlkjihgfedcbaZYXWVUTSRQPONMLKJIHGFEDCBA"
+	enc, err := NewEncoding(alphabet, NoPadding)
+	i.NoErr(err)
+	encoded := enc.EncodeToString([]byte("Hello"))
+	i.Equal(encoded, "t5qTk5D")
+	decoded, err := enc.DecodeString(encoded)
+	i.NoErr(err)
+	i.Equal(string(decoded), "Hello")
+
+	enc = MustNewEncoding(alphabet)
+	i.Equal(enc.EncodeToString([]byte("Hello")), "t5qTk5D=")
+
+	for _, tt := range []struct {
+		alphabet string
+		padding  []rune
+	}{
+		{StdAlphabet[:63], nil},
+		{StdAlphabet[:63] + "A", nil},
+		{StdAlphabet[:63] + "\n", nil},
+		{StdAlphabet, []rune{'+'}},
+		{StdAlphabet, []rune{'\r'}},
+		{StdAlphabet, []rune{'€'}},
+	} {
+		_, err := NewEncoding(tt.alphabet, tt.padding...)
+		i.True(errors.Is(err, ErrInvalidAlphabet))
+	}
+}
+
+func TestEncodingStream(t *testing.T) {
+	i := is.New(t)
+	data := bytes.Repeat([]byte("streaming base64 "), 1000)
+	for _, enc := range []Encoding{StdEncoding, RawURLEncoding, URLEncoding.WithPadding('*')} {
+		var buf bytes.Buffer
+		w := enc.NewEncoder(&buf)
+		for chunk := range slices.Chunk(data, 7) {
+			_, err := w.Write(chunk)
+			i.NoErr(err)
+		}
+		i.NoErr(w.Close())
+		i.Equal(buf.String(), enc.EncodeToString(data))
+
+		decoded, err := io.ReadAll(enc.NewDecoder(&buf))
+		i.NoErr(err)
+		i.Equal(decoded, data)
+	}
+}
+
+func TestEncodingStreamCorrupt(t *testing.T) {
+	i := is.New(t)
+	_, err := io.ReadAll(StdEncoding.NewDecoder(strings.NewReader("SGVsbG8*")))
+	var corrupt base64.CorruptInputError
+	i.True(errors.As(err, &corrupt))
+}
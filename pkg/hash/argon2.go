@@ -1,13 +1,12 @@
 package hash
 
 import (
-	"bytes"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
-
-	"golang.org/x/crypto/argon2"
 )
 
 type Argon2idParams struct {
@@ -24,8 +23,10 @@ var Argon2idDefaultParams = Argon2idParams{
 	KeyLen:      32,
 }
 
+const Argon2idSaltLen = 16
+
 func generateArgon2idSalt() ([]byte, error) {
-	salt := make([]byte, 16)
+	salt := make([]byte, Argon2idSaltLen)
 	_, err := rand.Read(salt)
 	if err != nil {
 		return nil, err
@@ -33,13 +34,181 @@ func generateArgon2idSalt() ([]byte, error) {
 	return salt, nil
 }
 
-func formatArgon2id(salt, hash []byte, p Argon2idParams) string {
-	return fmt.Sprintf("$argon2id$v=19$m=%d,t=%d,p=%d$%s$%s",
-		p.Memory,
-		p.Iterations,
-		p.Parallelism,
-		base64.RawStdEncoding.EncodeToString(salt),
-		base64.RawStdEncoding.EncodeToString(hash))
+const argon2idVersion = 19
+
+const (
+	// maxArgon2Memory and maxArgon2Iterations bound the params accepted from
+	// stored hashes, so a tampered hash cannot make Verify allocate or spin
+	// without limit. 1 GiB is far above any interactive login setting.
+	maxArgon2Memory     = 1 << 20
+	maxArgon2Iterations = maxCalibrationIterations
+)
+
+var (
+	ErrPepperRequired  = errors.New("hash is peppered: verify it with its pepper")
+	ErrUnsupportedData = errors.New("argon2 associated data is not supported")
+)
+
+type Argon2idHash struct {
+	Params Argon2idParams
+	KeyID  []byte
+	Data   []byte
+	Salt   []byte
+	Hash   []byte
+}
+
+func NewArgon2idHash(password, salt []byte, p Argon2idParams) Argon2idHash {
+	return Argon2idHash{
+		Params: p,
+		Salt:   salt,
+		Hash:   Argon2idBytesWithParams(password, salt, p),
+	}
+}
+
+func ParseArgon2id(hashedPassword string) (Argon2idHash, error) {
+	return parseArgon2("argon2id", hashedPassword)
+}
+
+func parseArgon2(id, hashedPassword string) (Argon2idHash, error) {
+	if !strings.HasPrefix(hashedPassword, "$") {
+		return Argon2idHash{}, ErrMissingPrefix
+	}
+
+	if fields := strings.Count(hashedPassword, "$") + 1; fields != 6 {
+		return Argon2idHash{}, fmt.Errorf("%w: expected 6 parts, got %d", ErrInvalidFieldCount, fields)
+	}
+
+	phc, err := ParsePHC(hashedPassword)
+	if err != nil {
+		return Argon2idHash{}, err
+	}
+
+	if phc.ID != id {
+		return Argon2idHash{}, fmt.Errorf("%w: expected %s, got %s", ErrUnsupportedAlgorithm, id, phc.ID)
+	}
+
+	if phc.Version != argon2idVersion {
+		return Argon2idHash{}, fmt.Errorf("%w: expected v=%d, got v=%d", ErrUnsupportedVersion, argon2idVersion, phc.Version)
+	}
+
+	h, err := parseArgon2idParams(phc.Params)
+	if err != nil {
+		return Argon2idHash{}, err
+	}
+
+	if len(phc.Salt) == 0 {
+		return Argon2idHash{}, ErrEmptySalt
+	}
+
+	if len(phc.Hash) == 0 {
+		return Argon2idHash{}, ErrEmptyHash
+	}
+
+	h.Salt = phc.Salt
+	h.Hash = phc.Hash
+	h.Params.KeyLen = uint32(len(phc.Hash))
+	return h, nil
+}
+
+func (h Argon2idHash) PHC() PHC {
+	params := []PHCParam{
+		{Key: "m", Value: strconv.FormatUint(uint64(h.Params.Memory), 10)},
+		{Key: "t", Value: strconv.FormatUint(uint64(h.Params.Iterations), 10)},
+		{Key: "p", Value: strconv.FormatUint(uint64(h.Params.Parallelism), 10)},
+	}
+	if len(h.KeyID) > 0 {
+		params = append(params, PHCParam{Key: "keyid", Value: base64.RawStdEncoding.EncodeToString(h.KeyID)})
+	}
+	if len(h.Data) > 0 {
+		params = append(params, PHCParam{Key: "data", Value: base64.RawStdEncoding.EncodeToString(h.Data)})
+	}
+	return PHC{
+		ID:      "argon2id",
+		Version: argon2idVersion,
+		Params:  params,
+		Salt:    h.Salt,
+		Hash:    h.Hash,
+	}
+}
+
+func (h Argon2idHash) String() string {
+	return h.PHC().String()
+}
+
+// Verify reports whether password matches the hash. Hashes with a keyid were
+// peppered and fail with ErrPepperRequired rather than never matching, see
+// PepperedArgon2idHasher. Hashes with associated data fail with ErrUnsupportedData.
+func (h Argon2idHash) Verify(password []byte) (bool, error) {
+	if len(h.KeyID) > 0 {
+		return false, fmt.Errorf("%w: keyid %s", ErrPepperRequired, h.KeyID)
+	}
+	return h.verify(password)
+}
+
+// verify compares the hash of password, which is already peppered if KeyID is set
+func (h Argon2idHash) verify(password []byte) (bool, error) {
+	if len(h.Data) > 0 {
+		return false, ErrUnsupportedData
+	}
+	params := h.Params
+	params.KeyLen = uint32(len(h.Hash))
+	return equalHashes(h.Hash, Argon2idBytesWithParams(password, h.Salt, params)), nil
+}
+
+func (h Argon2idHash) NeedsRehash(target Argon2idParams) bool {
+	return h.Params.Memory < target.Memory ||
+		h.Params.Iterations < target.Iterations ||
+		h.Params.Parallelism < target.Parallelism ||
+		uint32(len(h.Hash)) < target.KeyLen ||
+		len(h.Salt) < Argon2idSaltLen
+}
+
+func parseArgon2idParams(params []PHCParam) (Argon2idHash, error) {
+	if len(params) < 3 || params[0].Key != "m" || params[1].Key != "t" || params[2].Key != "p" {
+		return Argon2idHash{}, fmt.Errorf("%w: failed to parse m,t,p values", ErrMalformedParams)
+	}
+
+	memory, errM := strconv.ParseUint(params[0].Value, 10, 32)
+	iterations, errT := strconv.ParseUint(params[1].Value, 10, 32)
+	parallelism, errP := strconv.ParseUint(params[2].Value, 10, 8)
+	if errM != nil || errT != nil || errP != nil {
+		return Argon2idHash{}, fmt.Errorf("%w: failed to parse m,t,p values", ErrMalformedParams)
+	}
+
+	if memory == 0 || iterations == 0 || parallelism == 0 {
+		return Argon2idHash{}, fmt.Errorf("%w: values must be greater than 0", ErrInvalidParams)
+	}
+	if memory > maxArgon2Memory || iterations > maxArgon2Iterations {
+		return Argon2idHash{}, fmt.Errorf("%w: m must not exceed %d and t must not exceed %d", ErrInvalidParams, maxArgon2Memory, maxArgon2Iterations)
+	}
+
+	h := Argon2idHash{
+		Params: Argon2idParams{
+			Memory:      uint32(memory),
+			Iterations:  uint32(iterations),
+			Parallelism: uint8(parallelism),
+		},
+	}
+
+	for _, param := range params[3:] {
+		if param.Value == "" {
+			return Argon2idHash{}, fmt.Errorf("%w: empty %s", ErrMalformedParams, param.Key)
+		}
+		value, err := base64.RawStdEncoding.DecodeString(param.Value)
+		if err != nil {
+			return Argon2idHash{}, fmt.Errorf("%w in %s: %v", ErrIllegalBase64, param.Key, err)
+		}
+		switch {
+		case param.Key == "keyid" && h.KeyID == nil:
+			h.KeyID = value
+		case param.Key == "data" && h.Data == nil:
+			h.Data = value
+		default:
+			return Argon2idHash{}, fmt.Errorf("%w: unexpected parameter %q", ErrMalformedParams, param.Key)
+		}
+	}
+
+	return h, nil
 }
 
 func Argon2idBytes(password []byte) ([]byte, error) {
@@ -55,7 +224,7 @@ func Argon2idBytesWithSalt(password, salt []byte) []byte {
 }
 
 func Argon2idBytesWithParams(password, salt []byte, p Argon2idParams) []byte {
-	return argon2.IDKey(password, salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLen)
+	return argon2IDKey(password, salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLen)
 }
 
 func Argon2idBytesToString(password []byte) (string, error) {
@@ -63,18 +232,15 @@ func Argon2idBytesToString(password []byte) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	hash := Argon2idBytesWithParams(password, salt, Argon2idDefaultParams)
-	return formatArgon2id(salt, hash, Argon2idDefaultParams), nil
+	return NewArgon2idHash(password, salt, Argon2idDefaultParams).String(), nil
 }
 
 func Argon2idBytesToStringWithSalt(password, salt []byte) string {
-	hash := Argon2idBytesWithSalt(password, salt)
-	return formatArgon2id(salt, hash, Argon2idDefaultParams)
+	return NewArgon2idHash(password, salt, Argon2idDefaultParams).String()
 }
 
 func Argon2idBytesToStringWithParams(password, salt []byte, p Argon2idParams) string {
-	hash := Argon2idBytesWithParams(password, salt, p)
-	return formatArgon2id(salt, hash, p)
+	return NewArgon2idHash(password, salt, p).String()
 }
 
 func Argon2idString(password string) ([]byte, error) {
@@ -102,64 +268,12 @@ func Argon2idStringToStringWithParams(password string, salt []byte, p Argon2idPa
 }
 
 func VerifyArgon2id(hashedPassword string, password []byte) (bool, error) {
-	if len(hashedPassword) == 0 || !strings.HasPrefix(hashedPassword, "$") {
-		return false, fmt.Errorf("invalid hash format: must start with $")
-	}
-
-	parts := strings.Split(hashedPassword, "$")
-	if len(parts) != 6 {
-		return false, fmt.Errorf("invalid hash format: expected 6 parts, got %d", len(parts))
-	}
-
-	if parts[1] != "argon2id" {
-		return false, fmt.Errorf("invalid algorithm: expected argon2id, got %s", parts[1])
-	}
-
-	if parts[2] != "v=19" {
-		return false, fmt.Errorf("invalid version: expected v=19, got %s", parts[2])
-	}
-
-	var memory uint32
-	var iterations uint32
-	var parallelism uint8
-
-	paramCount, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism)
-	if err != nil || paramCount != 3 {
-		return false, fmt.Errorf("invalid parameters format: failed to parse m,t,p values")
-	}
-
-	if memory == 0 || iterations == 0 || parallelism == 0 {
-		return false, fmt.Errorf("invalid parameters: values must be greater than 0")
-	}
-
-	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	h, err := ParseArgon2id(hashedPassword)
 	if err != nil {
-		return false, fmt.Errorf("illegal base64")
-	}
-
-	if len(salt) == 0 {
-		return false, fmt.Errorf("invalid salt: cannot be empty")
+		DummyVerifyArgon2id(password)
+		return false, err
 	}
-
-	decodedHash, err := base64.RawStdEncoding.DecodeString(parts[5])
-	if err != nil {
-		return false, fmt.Errorf("illegal base64")
-	}
-
-	if len(decodedHash) == 0 {
-		return false, fmt.Errorf("invalid hash: cannot be empty")
-	}
-
-	params := Argon2idParams{
-		Memory:      memory,
-		Iterations:  iterations,
-		Parallelism: parallelism,
-		KeyLen:      uint32(len(decodedHash)),
-	}
-
-	computedHash := Argon2idBytesWithParams(password, salt, params)
-
-	return bytes.Equal(decodedHash, computedHash), nil
+	return h.Verify(password)
 }
 
 func VerifyArgon2idString(hashedPassword, password string) (bool, error) {
@@ -177,3 +291,37 @@ func MustVerifyArgon2id(hashedPassword string, password []byte) bool {
 func MustVerifyArgon2idString(hashedPassword, password string) bool {
 	return MustVerifyArgon2id(hashedPassword, []byte(password))
 }
+
+func Argon2idNeedsRehash(hashedPassword string, target Argon2idParams) (bool, error) {
+	h, err := ParseArgon2id(hashedPassword)
+	if err != nil {
+		return false, err
+	}
+	return h.NeedsRehash(target), nil
+}
+
+func VerifyArgon2idAndRehash(hashedPassword string, password []byte, target Argon2idParams) (bool, string, error) {
+	h, err := ParseArgon2id(hashedPassword)
+	if err != nil {
+		DummyVerifyArgon2id(password)
+		return false, "", err
+	}
+	if ok, err := h.Verify(password); !ok || err != nil {
+		return false, "", err
+	}
+	if !h.NeedsRehash(target) {
+		return true, "", nil
+	}
+
+	salt, err := generateArgon2idSalt()
+	if err != nil {
+		return true, "", err
+	}
+	// a zero KeyLen sets no minimum, keep the length of the existing hash
+	target.KeyLen = max(target.KeyLen, uint32(len(h.Hash)))
+	return true, NewArgon2idHash(password, salt, target).String(), nil
+}
+
+func VerifyArgon2idStringAndRehash(hashedPassword, password string, target Argon2idParams) (bool, string, error) {
+	return VerifyArgon2idAndRehash(hashedPassword, []byte(password), target)
+}
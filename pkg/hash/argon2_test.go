@@ -3,6 +3,7 @@ package hash
 import (
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"strings"
 	"testing"
 
@@ -343,3 +344,164 @@ func TestVerifyArgon2idWithUnicode(t *testing.T) {
 	i.NoErr(err)
 	i.True(!match)
 }
+
+func TestParseArgon2id(t *testing.T) {
+	i := is.New(t)
+	salt := []byte("0123456789abcdef")
+	params := Argon2idParams{
+		Memory:      64,
+		Iterations:  2,
+		Parallelism: 1,
+		KeyLen:      24,
+	}
+
+	h, err := ParseArgon2id(Argon2idStringToStringWithParams("password", salt, params))
+	i.NoErr(err)
+	i.Equal(h.Params, params)
+	i.Equal(h.Salt, salt)
+	i.Equal(len(h.Hash), 24)
+	ok, err := h.Verify([]byte("password"))
+	i.NoErr(err)
+	i.True(ok)
+	ok, err = h.Verify([]byte("wrongpassword"))
+	i.NoErr(err)
+	i.True(!ok)
+}
+
+func TestParseArgon2idExtraParams(t *testing.T) {
+	i := is.New(t)
+	h := NewArgon2idHash([]byte("password"), []byte("0123456789abcdef"), Argon2idParams{
+		Memory:      64,
+		Iterations:  1,
+		Parallelism: 1,
+		KeyLen:      16,
+	})
+	h.KeyID = []byte("key-1")
+	h.Data = []byte("tenant")
+
+	encoded := h.String()
+	i.True(strings.Contains(encoded, ",keyid=a2V5LTE,data=dGVuYW50$"))
+
+	parsed, err := ParseArgon2id(encoded)
+	i.NoErr(err)
+	i.Equal(string(parsed.KeyID), "key-1")
+	i.Equal(string(parsed.Data), "tenant")
+	i.Equal(parsed.String(), encoded)
+
+	_, err = parsed.Verify([]byte("password"))
+	i.True(errors.Is(err, ErrPepperRequired))
+	_, err = VerifyArgon2id(encoded, []byte("password"))
+	i.True(errors.Is(err, ErrPepperRequired))
+
+	parsed.KeyID = nil
+	_, err = parsed.Verify([]byte("password"))
+	i.True(errors.Is(err, ErrUnsupportedData))
+}
+
+func TestParseArgon2idSentinelErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		hash     string
+		expected error
+	}{
+		{name: "no prefix", hash: "argon2id", expected: ErrMissingPrefix},
+		{name: "field count", hash: "$argon2id$v=19", expected: ErrInvalidFieldCount},
+		{name: "algorithm", hash: "$argon2i$v=19$m=2,t=1,p=4$c2FsdA$aGFzaA", expected: ErrUnsupportedAlgorithm},
+		{name: "version", hash: "$argon2id$v=16$m=2,t=1,p=4$c2FsdA$aGFzaA", expected: ErrUnsupportedVersion},
+		{name: "params format", hash: "$argon2id$v=19$t=1,m=2,p=4$c2FsdA$aGFzaA", expected: ErrMalformedParams},
+		{name: "params overflow", hash: "$argon2id$v=19$m=2,t=1,p=256$c2FsdA$aGFzaA", expected: ErrMalformedParams},
+		{name: "unknown param", hash: "$argon2id$v=19$m=2,t=1,p=4,x=eA$c2FsdA$aGFzaA", expected: ErrMalformedParams},
+		{name: "empty keyid", hash: "$argon2id$v=19$m=2,t=1,p=4,keyid=$c2FsdA$aGFzaA", expected: ErrMalformedParams},
+		{name: "empty data", hash: "$argon2id$v=19$m=2,t=1,p=4,data=$c2FsdA$aGFzaA", expected: ErrMalformedParams},
+		{name: "params value", hash: "$argon2id$v=19$m=0,t=1,p=4$c2FsdA$aGFzaA", expected: ErrInvalidParams},
+		{name: "memory bound", hash: "$argon2id$v=19$m=4194304,t=1,p=4$c2FsdA$aGFzaA", expected: ErrInvalidParams},
+		{name: "iterations bound", hash: "$argon2id$v=19$m=64,t=4294967295,p=4$c2FsdA$aGFzaA", expected: ErrInvalidParams},
+		{name: "base64", hash: "$argon2id$v=19$m=2,t=1,p=4$c2FsdA$>>>", expected: ErrIllegalBase64},
+		{name: "salt", hash: "$argon2id$v=19$m=2,t=1,p=4$$aGFzaA", expected: ErrEmptySalt},
+		{name: "hash", hash: "$argon2id$v=19$m=2,t=1,p=4$c2FsdA$", expected: ErrEmptyHash},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			_, err := ParseArgon2id(tc.hash)
+			is.True(errors.Is(err, tc.expected))
+		})
+	}
+}
+
+func TestArgon2idNeedsRehash(t *testing.T) {
+	i := is.New(t)
+	salt := []byte("0123456789abcdef")
+	params := Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, KeyLen: 16}
+	hashedPassword := Argon2idStringToStringWithParams("password", salt, params)
+
+	testCases := []struct {
+		name     string
+		target   Argon2idParams
+		expected bool
+	}{
+		{name: "same params", target: params, expected: false},
+		{name: "weaker target", target: Argon2idParams{Memory: 32, Iterations: 1, Parallelism: 1, KeyLen: 16}, expected: false},
+		{name: "more memory", target: Argon2idParams{Memory: 128, Iterations: 1, Parallelism: 1, KeyLen: 16}, expected: true},
+		{name: "more iterations", target: Argon2idParams{Memory: 64, Iterations: 2, Parallelism: 1, KeyLen: 16}, expected: true},
+		{name: "more parallelism", target: Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 2, KeyLen: 16}, expected: true},
+		{name: "longer key", target: Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, KeyLen: 32}, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			needsRehash, err := Argon2idNeedsRehash(hashedPassword, tc.target)
+			is.NoErr(err)
+			is.Equal(needsRehash, tc.expected)
+		})
+	}
+
+	shortSalt := Argon2idStringToStringWithParams("password", []byte("short"), params)
+	needsRehash, err := Argon2idNeedsRehash(shortSalt, params)
+	i.NoErr(err)
+	i.True(needsRehash)
+}
+
+func TestVerifyArgon2idAndRehash(t *testing.T) {
+	i := is.New(t)
+	oldParams := Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, KeyLen: 16}
+	newParams := Argon2idParams{Memory: 128, Iterations: 2, Parallelism: 1, KeyLen: 32}
+	hashedPassword := Argon2idStringToStringWithParams("password", []byte("0123456789abcdef"), oldParams)
+
+	match, rehashed, err := VerifyArgon2idStringAndRehash(hashedPassword, "wrongpassword", newParams)
+	i.NoErr(err)
+	i.True(!match)
+	i.Equal(rehashed, "")
+
+	match, rehashed, err = VerifyArgon2idStringAndRehash(hashedPassword, "password", newParams)
+	i.NoErr(err)
+	i.True(match)
+	i.True(strings.Contains(rehashed, "m=128,t=2,p=1"))
+
+	h, err := ParseArgon2id(rehashed)
+	i.NoErr(err)
+	i.Equal(h.Params, newParams)
+	ok, err := h.Verify([]byte("password"))
+	i.NoErr(err)
+	i.True(ok)
+
+	match, rehashed, err = VerifyArgon2idStringAndRehash(rehashed, "password", newParams)
+	i.NoErr(err)
+	i.True(match)
+	i.Equal(rehashed, "")
+}
+
+func TestVerifyArgon2idAndRehashZeroKeyLen(t *testing.T) {
+	i := is.New(t)
+	oldParams := Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, KeyLen: 16}
+	hashedPassword := Argon2idStringToStringWithParams("password", []byte("0123456789abcdef"), oldParams)
+
+	match, rehashed, err := VerifyArgon2idStringAndRehash(hashedPassword, "password", Argon2idParams{Memory: 128, Iterations: 1, Parallelism: 1})
+	i.NoErr(err)
+	i.True(match)
+	h, err := ParseArgon2id(rehashed)
+	i.NoErr(err)
+	i.Equal(h.Params, Argon2idParams{Memory: 128, Iterations: 1, Parallelism: 1, KeyLen: 16})
+}
@@ -0,0 +1,49 @@
+package hash
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const BcryptDefaultCost = bcrypt.DefaultCost
+
+// BcryptHasher hashes passwords with bcrypt and verifies $2a$, $2b$ and $2y$ hashes
+type BcryptHasher struct {
+	Cost int
+}
+
+func NewBcryptHasher(cost int) *BcryptHasher {
+	return &BcryptHasher{Cost: cost}
+}
+
+func (h *BcryptHasher) Prefixes() []string {
+	return []string{"2a", "2b", "2y"}
+}
+
+func (h *BcryptHasher) Hash(password []byte) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword(password, h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func (h *BcryptHasher) Verify(hashedPassword string, password []byte) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), password)
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (h *BcryptHasher) NeedsRehash(hashedPassword string) (bool, error) {
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	if err != nil {
+		return false, err
+	}
+	return cost < h.Cost, nil
+}
@@ -0,0 +1,45 @@
+package hash
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestBcryptHasher(t *testing.T) {
+	i := is.New(t)
+	h := NewBcryptHasher(4)
+
+	hashed, err := h.Hash([]byte("password"))
+	i.NoErr(err)
+	i.True(strings.HasPrefix(hashed, "$2a$04$"))
+
+	match, err := h.Verify(hashed, []byte("password"))
+	i.NoErr(err)
+	i.True(match)
+
+	match, err = h.Verify(hashed, []byte("wrongpassword"))
+	i.NoErr(err)
+	i.True(!match)
+}
+
+func TestBcryptHasherNeedsRehash(t *testing.T) {
+	i := is.New(t)
+	hashed, err := NewBcryptHasher(4).Hash([]byte("password"))
+	i.NoErr(err)
+
+	needsRehash, err := NewBcryptHasher(5).NeedsRehash(hashed)
+	i.NoErr(err)
+	i.True(needsRehash)
+
+	needsRehash, err = NewBcryptHasher(4).NeedsRehash(hashed)
+	i.NoErr(err)
+	i.True(!needsRehash)
+}
+
+func TestBcryptHasherInvalidHash(t *testing.T) {
+	i := is.New(t)
+	_, err := NewBcryptHasher(4).Verify("$2b$invalid", []byte("password"))
+	i.True(err != nil)
+}
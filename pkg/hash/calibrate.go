@@ -0,0 +1,80 @@
+package hash
+
+import (
+	"errors"
+	"time"
+)
+
+const maxCalibrationIterations = 1 << 16
+
+var ErrInvalidCalibration = errors.New("invalid calibration: target must be positive and max memory at least 8 KiB per lane")
+
+// measureArgon2id is a variable so tests can calibrate against a fake clock
+var measureArgon2id = func(p Argon2idParams) time.Duration {
+	start := time.Now()
+	Argon2idBytesWithParams([]byte("calibration"), dummySalt, p)
+	return time.Since(start)
+}
+
+// CalibrationMeasurement is a single timed Argon2id run
+type CalibrationMeasurement struct {
+	Params   Argon2idParams
+	Duration time.Duration
+}
+
+// CalibrationReport holds the params chosen by Calibrate and every measurement taken on the way
+type CalibrationReport struct {
+	Target       time.Duration
+	Params       Argon2idParams
+	Duration     time.Duration
+	Measurements []CalibrationMeasurement
+}
+
+// Calibrate measures Argon2id on the current machine and returns the params
+// with the most memory (up to maxMemory KiB) and then the most iterations whose
+// runtime stays within target. Parallelism and key length are taken from
+// Argon2idDefaultParams. If even a single iteration with the minimum memory is
+// slower than target, those minimal params are returned.
+func Calibrate(target time.Duration, maxMemory uint32) (CalibrationReport, error) {
+	params := Argon2idDefaultParams
+	minMemory := 8 * uint32(params.Parallelism)
+	if target <= 0 || maxMemory < minMemory {
+		return CalibrationReport{}, ErrInvalidCalibration
+	}
+
+	report := CalibrationReport{Target: target}
+	measure := func(p Argon2idParams) time.Duration {
+		d := measureArgon2id(p)
+		report.Measurements = append(report.Measurements, CalibrationMeasurement{Params: p, Duration: d})
+		return d
+	}
+
+	// hashes with more memory would be rejected when parsed
+	params.Memory = min(maxMemory, maxArgon2Memory)
+	params.Iterations = 1
+	duration := measure(params)
+	for duration > target && params.Memory/2 >= minMemory {
+		params.Memory /= 2
+		duration = measure(params)
+	}
+
+	if duration <= target {
+		single := duration
+		if single <= 0 {
+			single = 1
+		}
+		if iterations := min(target/single, maxCalibrationIterations); iterations > 1 {
+			params.Iterations = uint32(iterations)
+			duration = measure(params)
+			for duration > target && params.Iterations > 1 {
+				next := uint32(time.Duration(params.Iterations) * target / duration)
+				params.Iterations = max(min(next, params.Iterations-1), 1)
+				duration = measure(params)
+			}
+		}
+	}
+
+	report.Params = params
+	report.Duration = duration
+	return report, nil
+}
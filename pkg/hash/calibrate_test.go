@@ -0,0 +1,75 @@
+package hash
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// fakeArgon2idCost makes every KiB per iteration cost one microsecond
+func fakeArgon2idCost(t *testing.T) {
+	original := measureArgon2id
+	measureArgon2id = func(p Argon2idParams) time.Duration {
+		return time.Duration(p.Memory) * time.Duration(p.Iterations) * time.Microsecond
+	}
+	t.Cleanup(func() { measureArgon2id = original })
+}
+
+func TestCalibrateIncreasesIterations(t *testing.T) {
+	i := is.New(t)
+	fakeArgon2idCost(t)
+
+	report, err := Calibrate(500*time.Millisecond, 64*1024)
+	i.NoErr(err)
+	i.Equal(report.Params.Memory, uint32(64*1024))
+	i.Equal(report.Params.Iterations, uint32(7))
+	i.Equal(report.Params.Parallelism, Argon2idDefaultParams.Parallelism)
+	i.Equal(report.Params.KeyLen, Argon2idDefaultParams.KeyLen)
+	i.True(report.Duration <= report.Target)
+	i.Equal(report.Measurements[len(report.Measurements)-1].Params, report.Params)
+}
+
+func TestCalibrateReducesMemory(t *testing.T) {
+	i := is.New(t)
+	fakeArgon2idCost(t)
+
+	report, err := Calibrate(20*time.Millisecond, 64*1024)
+	i.NoErr(err)
+	i.Equal(report.Params.Memory, uint32(16*1024))
+	i.Equal(report.Params.Iterations, uint32(1))
+	i.Equal(report.Duration, 16384*time.Microsecond)
+	i.Equal(len(report.Measurements), 3)
+}
+
+func TestCalibrateMinimumParams(t *testing.T) {
+	i := is.New(t)
+	fakeArgon2idCost(t)
+
+	report, err := Calibrate(time.Microsecond, 1024)
+	i.NoErr(err)
+	i.Equal(report.Params.Memory, uint32(32))
+	i.Equal(report.Params.Iterations, uint32(1))
+	i.True(report.Duration > report.Target)
+}
+
+func TestCalibrateInvalid(t *testing.T) {
+	i := is.New(t)
+
+	_, err := Calibrate(0, 64*1024)
+	i.True(errors.Is(err, ErrInvalidCalibration))
+
+	_, err = Calibrate(time.Second, 16)
+	i.True(errors.Is(err, ErrInvalidCalibration))
+}
+
+func TestCalibrateMeasuresHost(t *testing.T) {
+	i := is.New(t)
+
+	report, err := Calibrate(20*time.Millisecond, 1024)
+	i.NoErr(err)
+	i.True(len(report.Measurements) > 0)
+	i.True(report.Params.Memory <= 1024)
+	i.True(report.Params.Iterations >= 1)
+}
@@ -0,0 +1,80 @@
+package hash
+
+import "context"
+
+// runContext runs fn on its own goroutine and returns ctx.Err() as soon as ctx
+// is done. The key derivation functions cannot be interrupted, so fn keeps
+// running in the background and its result is discarded.
+func runContext[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn()
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+func Argon2idBytesWithParamsContext(ctx context.Context, password, salt []byte, p Argon2idParams) ([]byte, error) {
+	return runContext(ctx, func() ([]byte, error) {
+		return Argon2idBytesWithParams(password, salt, p), nil
+	})
+}
+
+func Argon2idBytesToStringWithParamsContext(ctx context.Context, password, salt []byte, p Argon2idParams) (string, error) {
+	return runContext(ctx, func() (string, error) {
+		return Argon2idBytesToStringWithParams(password, salt, p), nil
+	})
+}
+
+func Argon2idStringToStringWithParamsContext(ctx context.Context, password string, salt []byte, p Argon2idParams) (string, error) {
+	return Argon2idBytesToStringWithParamsContext(ctx, []byte(password), salt, p)
+}
+
+func VerifyArgon2idContext(ctx context.Context, hashedPassword string, password []byte) (bool, error) {
+	return runContext(ctx, func() (bool, error) {
+		return VerifyArgon2id(hashedPassword, password)
+	})
+}
+
+func VerifyArgon2idStringContext(ctx context.Context, hashedPassword, password string) (bool, error) {
+	return VerifyArgon2idContext(ctx, hashedPassword, []byte(password))
+}
+
+// HashContext is like Hash but returns ctx.Err() once ctx is done
+func (r *Registry) HashContext(ctx context.Context, password []byte) (string, error) {
+	return runContext(ctx, func() (string, error) {
+		return r.Hash(password)
+	})
+}
+
+// VerifyContext is like Verify but returns ctx.Err() once ctx is done
+func (r *Registry) VerifyContext(ctx context.Context, hashedPassword string, password []byte) (bool, error) {
+	return runContext(ctx, func() (bool, error) {
+		return r.Verify(hashedPassword, password)
+	})
+}
+
+// HashContext hashes password with DefaultRegistry and returns ctx.Err() once ctx is done
+func HashContext(ctx context.Context, password []byte) (string, error) {
+	return DefaultRegistry.HashContext(ctx, password)
+}
+
+// VerifyContext verifies password with DefaultRegistry and returns ctx.Err() once ctx is done
+func VerifyContext(ctx context.Context, hashedPassword string, password []byte) (bool, error) {
+	return DefaultRegistry.VerifyContext(ctx, hashedPassword, password)
+}
@@ -0,0 +1,124 @@
+package hash
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// blockArgon2IDKey makes every Argon2id computation block until the test ends.
+// The returned channel receives a value whenever a computation started.
+func blockArgon2IDKey(t *testing.T) <-chan struct{} {
+	started := make(chan struct{}, 10)
+	unblock := make(chan struct{})
+	original := argon2IDKey
+	argon2IDKey = func(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+		started <- struct{}{}
+		<-unblock
+		return original(password, salt, time, memory, threads, keyLen)
+	}
+	t.Cleanup(func() {
+		close(unblock)
+		argon2IDKey = original
+	})
+	return started
+}
+
+func TestArgon2idContext(t *testing.T) {
+	i := is.New(t)
+	salt := []byte("0123456789abcdef")
+
+	hashed, err := Argon2idStringToStringWithParamsContext(context.Background(), "password", salt, testArgon2idParams)
+	i.NoErr(err)
+	i.Equal(hashed, Argon2idStringToStringWithParams("password", salt, testArgon2idParams))
+
+	match, err := VerifyArgon2idStringContext(context.Background(), hashed, "password")
+	i.NoErr(err)
+	i.True(match)
+
+	raw, err := Argon2idBytesWithParamsContext(context.Background(), []byte("password"), salt, testArgon2idParams)
+	i.NoErr(err)
+	i.Equal(len(raw), 32)
+}
+
+func TestContextCanceledBeforeStart(t *testing.T) {
+	i := is.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	original := argon2IDKey
+	argon2IDKey = func(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+		calls++
+		return original(password, salt, time, memory, threads, keyLen)
+	}
+	t.Cleanup(func() { argon2IDKey = original })
+
+	_, err := HashContext(ctx, []byte("password"))
+	i.True(errors.Is(err, context.Canceled))
+	_, err = VerifyContext(ctx, "$argon2id$v=19$m=64,t=1,p=1$c2FsdA$aGFzaA", []byte("password"))
+	i.True(errors.Is(err, context.Canceled))
+	i.Equal(calls, 0)
+}
+
+func TestContextReturnsPromptly(t *testing.T) {
+	i := is.New(t)
+	hashed := Argon2idStringToStringWithParams("password", []byte("0123456789abcdef"), testArgon2idParams)
+	started := blockArgon2IDKey(t)
+	r := newTestRegistry()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := r.HashContext(ctx, []byte("password"))
+	i.True(errors.Is(err, context.DeadlineExceeded))
+	<-started
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = r.VerifyContext(ctx, hashed, []byte("password"))
+	i.True(errors.Is(err, context.DeadlineExceeded))
+	<-started
+}
+
+func TestRegistryContext(t *testing.T) {
+	i := is.New(t)
+	r := newTestRegistry()
+
+	hashed, err := r.HashContext(context.Background(), []byte("password"))
+	i.NoErr(err)
+	i.True(strings.HasPrefix(hashed, "$argon2id$"))
+
+	match, err := r.VerifyContext(context.Background(), hashed, []byte("password"))
+	i.NoErr(err)
+	i.True(match)
+}
+
+func TestLimiterHoldsSlotUntilComputationFinishes(t *testing.T) {
+	i := is.New(t)
+	unblock := make(chan struct{})
+	finished := make(chan struct{})
+	original := argon2IDKey
+	argon2IDKey = func(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+		defer close(finished)
+		<-unblock
+		return original(password, salt, time, memory, threads, keyLen)
+	}
+	t.Cleanup(func() { argon2IDKey = original })
+
+	l := NewLimiter(1, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := l.Argon2idBytesWithParams(ctx, []byte("password"), []byte("0123456789abcdef"), testArgon2idParams)
+	i.True(errors.Is(err, context.DeadlineExceeded))
+	i.Equal(l.Stats().Active, 1)
+
+	close(unblock)
+	<-finished
+	for l.Stats().Active != 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
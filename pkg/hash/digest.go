@@ -0,0 +1,237 @@
+package hash
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	stdhash "hash"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dario.lol/gotils/pkg/file"
+	"golang.org/x/crypto/blake2b"
+)
+
+type DigestAlgorithm string
+
+const (
+	DigestSHA256     DigestAlgorithm = "sha256"
+	DigestSHA512     DigestAlgorithm = "sha512"
+	DigestBLAKE2b256 DigestAlgorithm = "blake2b-256"
+	DigestBLAKE2b512 DigestAlgorithm = "blake2b-512"
+	DigestCRC32      DigestAlgorithm = "crc32"
+	DigestXXH64      DigestAlgorithm = "xxh64"
+)
+
+var (
+	ErrUnsupportedDigest = errors.New("unsupported digest algorithm")
+	ErrDigestMismatch    = errors.New("digest mismatch")
+	ErrMalformedManifest = errors.New("malformed checksum manifest")
+)
+
+// NewDigest returns a streaming hash for alg
+func NewDigest(alg DigestAlgorithm) (stdhash.Hash, error) {
+	switch alg {
+	case DigestSHA256:
+		return sha256.New(), nil
+	case DigestSHA512:
+		return sha512.New(), nil
+	case DigestBLAKE2b256:
+		return blake2b.New256(nil)
+	case DigestBLAKE2b512:
+		return blake2b.New512(nil)
+	case DigestCRC32:
+		return crc32.NewIEEE(), nil
+	case DigestXXH64:
+		return NewXXH64(0), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDigest, alg)
+	}
+}
+
+// Digest returns the digest of data
+func Digest(alg DigestAlgorithm, data []byte) ([]byte, error) {
+	return DigestReader(alg, bytes.NewReader(data))
+}
+
+// DigestString returns the digest of data
+func DigestString(alg DigestAlgorithm, data string) ([]byte, error) {
+	return DigestReader(alg, strings.NewReader(data))
+}
+
+// DigestHex returns the lowercase hex encoded digest of data
+func DigestHex(alg DigestAlgorithm, data []byte) (string, error) {
+	digest, err := Digest(alg, data)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(digest), nil
+}
+
+// DigestReader returns the digest of everything read from r without buffering it
+func DigestReader(alg DigestAlgorithm, r io.Reader) ([]byte, error) {
+	h, err := NewDigest(alg)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// DigestFile streams the file at path through alg
+func DigestFile(alg DigestAlgorithm, path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DigestReader(alg, f)
+}
+
+// VerifyDigest reports whether data has the hex encoded digest expected
+func VerifyDigest(alg DigestAlgorithm, data []byte, expected string) (bool, error) {
+	return VerifyDigestReader(alg, bytes.NewReader(data), expected)
+}
+
+// VerifyDigestReader reports whether everything read from r has the hex encoded digest expected
+func VerifyDigestReader(alg DigestAlgorithm, r io.Reader, expected string) (bool, error) {
+	want, err := hex.DecodeString(strings.TrimSpace(expected))
+	if err != nil {
+		return false, err
+	}
+	got, err := DigestReader(alg, r)
+	if err != nil {
+		return false, err
+	}
+	return equalHashes(got, want), nil
+}
+
+// VerifyFileDigest reports whether the file at path has the hex encoded digest expected
+func VerifyFileDigest(alg DigestAlgorithm, path, expected string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	return VerifyDigestReader(alg, f, expected)
+}
+
+// ReadFileVerified is like file.Read but fails with ErrDigestMismatch if the
+// content does not have the hex encoded digest expected
+func ReadFileVerified(alg DigestAlgorithm, path, expected string) ([]byte, error) {
+	data, err := file.Read(path)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := VerifyDigest(alg, data, expected)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDigestMismatch, path)
+	}
+	return data, nil
+}
+
+// ChecksumEntry is a single line of a sha256sum style manifest
+type ChecksumEntry struct {
+	Digest []byte
+	Path   string
+}
+
+// ChecksumResult is the outcome of checking a single manifest entry
+type ChecksumResult struct {
+	Path string
+	OK   bool
+	Err  error
+}
+
+// ParseChecksums parses a sha256sum style manifest of "<hex digest>  <path>"
+// lines. Binary mode markers ("<hex digest> *<path>") and blank lines are accepted.
+func ParseChecksums(r io.Reader) ([]ChecksumEntry, error) {
+	var entries []ChecksumEntry
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		entry, ok, err := parseChecksumLine(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrMalformedManifest, line, err)
+		}
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// ReadChecksums reads and parses the manifest file at path
+func ReadChecksums(path string) ([]ChecksumEntry, error) {
+	content, err := file.ReadString(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseChecksums(strings.NewReader(content))
+}
+
+// VerifyChecksums checks every file listed in the manifest at manifestPath.
+// Relative paths are resolved against the manifest's directory.
+func VerifyChecksums(alg DigestAlgorithm, manifestPath string) ([]ChecksumResult, error) {
+	entries, err := ReadChecksums(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(manifestPath)
+	results := make([]ChecksumResult, 0, len(entries))
+	for _, entry := range entries {
+		path := entry.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		digest, err := DigestFile(alg, path)
+		results = append(results, ChecksumResult{
+			Path: entry.Path,
+			OK:   err == nil && equalHashes(digest, entry.Digest),
+			Err:  err,
+		})
+	}
+	return results, nil
+}
+
+// FormatChecksums formats entries as a sha256sum style manifest
+func FormatChecksums(entries []ChecksumEntry) string {
+	var builder strings.Builder
+	for _, entry := range entries {
+		builder.WriteString(hex.EncodeToString(entry.Digest))
+		builder.WriteString("  ")
+		builder.WriteString(entry.Path)
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
+
+func parseChecksumLine(line string) (ChecksumEntry, bool, error) {
+	line = strings.TrimRight(line, "\r")
+	if strings.TrimSpace(line) == "" {
+		return ChecksumEntry{}, false, nil
+	}
+
+	digest, path, ok := strings.Cut(line, " ")
+	if !ok || len(path) < 2 || (path[0] != ' ' && path[0] != '*') {
+		return ChecksumEntry{}, false, errors.New("expected \"<digest>  <path>\"")
+	}
+
+	decoded, err := hex.DecodeString(digest)
+	if err != nil {
+		return ChecksumEntry{}, false, err
+	}
+	return ChecksumEntry{Digest: decoded, Path: path[1:]}, true, nil
+}
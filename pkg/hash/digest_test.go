@@ -0,0 +1,113 @@
+package hash
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestDigestVectors(t *testing.T) {
+	testCases := []struct {
+		alg      DigestAlgorithm
+		input    string
+		expected string
+	}{
+		{alg: DigestSHA256, input: "abc", expected: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{alg: DigestSHA512, input: "abc", expected: "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+		{alg: DigestBLAKE2b512, input: "abc", expected: "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"},
+		{alg: DigestCRC32, input: "123456789", expected: "cbf43926"},
+		{alg: DigestXXH64, input: "abc", expected: "44bc2cf5ad770999"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.alg), func(t *testing.T) {
+			is := is.New(t)
+			digest, err := DigestString(tc.alg, tc.input)
+			is.NoErr(err)
+			is.Equal(hex.EncodeToString(digest), tc.expected)
+
+			ok, err := VerifyDigestReader(tc.alg, strings.NewReader(tc.input), tc.expected)
+			is.NoErr(err)
+			is.True(ok)
+		})
+	}
+}
+
+func TestDigestUnsupported(t *testing.T) {
+	i := is.New(t)
+	_, err := DigestString("md5", "abc")
+	i.True(errors.Is(err, ErrUnsupportedDigest))
+}
+
+func TestDigestFile(t *testing.T) {
+	i := is.New(t)
+	path := filepath.Join(t.TempDir(), "artifact.bin")
+	i.NoErr(os.WriteFile(path, []byte("abc"), 0644))
+
+	digest, err := DigestFile(DigestSHA256, path)
+	i.NoErr(err)
+	i.Equal(hex.EncodeToString(digest), "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad")
+
+	ok, err := VerifyFileDigest(DigestSHA256, path, "BA7816BF8F01CFEA414140DE5DAE2223B00361A396177A9CB410FF61F20015AD")
+	i.NoErr(err)
+	i.True(ok)
+
+	data, err := ReadFileVerified(DigestSHA256, path, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad")
+	i.NoErr(err)
+	i.Equal(string(data), "abc")
+
+	_, err = ReadFileVerified(DigestSHA256, path, strings.Repeat("00", 32))
+	i.True(errors.Is(err, ErrDigestMismatch))
+}
+
+func TestParseChecksums(t *testing.T) {
+	i := is.New(t)
+	manifest := "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad  a.txt\n" +
+		"\n" +
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855 *dir/with space.bin\r\n"
+
+	entries, err := ParseChecksums(strings.NewReader(manifest))
+	i.NoErr(err)
+	i.Equal(len(entries), 2)
+	i.Equal(entries[0].Path, "a.txt")
+	i.Equal(entries[1].Path, "dir/with space.bin")
+	i.Equal(hex.EncodeToString(entries[1].Digest), "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+
+	_, err = ParseChecksums(strings.NewReader("nothex  a.txt\n"))
+	i.True(errors.Is(err, ErrMalformedManifest))
+
+	_, err = ParseChecksums(strings.NewReader("ba7816bf\n"))
+	i.True(errors.Is(err, ErrMalformedManifest))
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	i := is.New(t)
+	dir := t.TempDir()
+	i.NoErr(os.WriteFile(filepath.Join(dir, "good.txt"), []byte("abc"), 0644))
+	i.NoErr(os.WriteFile(filepath.Join(dir, "bad.txt"), []byte("abd"), 0644))
+
+	abc, err := DigestString(DigestSHA256, "abc")
+	i.NoErr(err)
+	manifest := FormatChecksums([]ChecksumEntry{
+		{Digest: abc, Path: "good.txt"},
+		{Digest: abc, Path: "bad.txt"},
+		{Digest: abc, Path: "missing.txt"},
+	})
+	manifestPath := filepath.Join(dir, "SHA256SUMS")
+	i.NoErr(os.WriteFile(manifestPath, []byte(manifest), 0644))
+
+	results, err := VerifyChecksums(DigestSHA256, manifestPath)
+	i.NoErr(err)
+	i.Equal(len(results), 3)
+	i.True(results[0].OK)
+	i.NoErr(results[0].Err)
+	i.True(!results[1].OK)
+	i.NoErr(results[1].Err)
+	i.True(!results[2].OK)
+	i.True(errors.Is(results[2].Err, os.ErrNotExist))
+}
@@ -0,0 +1,284 @@
+package hash
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Hasher hashes passwords into self-describing strings and verifies passwords against them
+type Hasher interface {
+	// Prefixes returns the identifiers, without surrounding $ signs, of the hashes this hasher can verify
+	Prefixes() []string
+	Hash(password []byte) (string, error)
+	Verify(hashedPassword string, password []byte) (bool, error)
+}
+
+// RehashChecker is implemented by hashers that can tell whether a hash they
+// produced was created with weaker settings than they currently use
+type RehashChecker interface {
+	NeedsRehash(hashedPassword string) (bool, error)
+}
+
+// Registry dispatches verification to the hasher matching a hash's prefix and
+// creates new hashes with its preferred hasher
+type Registry struct {
+	preferred Hasher
+	hashers   map[string]Hasher
+}
+
+// NewRegistry creates a registry hashing with preferred and verifying hashes of preferred and all others
+func NewRegistry(preferred Hasher, others ...Hasher) *Registry {
+	r := &Registry{
+		preferred: preferred,
+		hashers:   make(map[string]Hasher),
+	}
+	for _, h := range others {
+		for _, prefix := range h.Prefixes() {
+			r.hashers[prefix] = h
+		}
+	}
+	for _, prefix := range preferred.Prefixes() {
+		r.hashers[prefix] = preferred
+	}
+	return r
+}
+
+// DefaultRegistry hashes with Argon2id and verifies Argon2id, Argon2i, bcrypt, scrypt and PBKDF2 hashes.
+// Its Argon2 hashers have zero Params, so they follow changes to Argon2idDefaultParams.
+var DefaultRegistry = NewRegistry(
+	NewArgon2idHasher(Argon2idParams{}),
+	NewArgon2iHasher(Argon2idParams{}),
+	NewBcryptHasher(BcryptDefaultCost),
+	NewScryptHasher(ScryptDefaultParams),
+	NewPBKDF2Hasher(PBKDF2DefaultParams),
+)
+
+// Hash hashes password with the preferred hasher
+func (r *Registry) Hash(password []byte) (string, error) {
+	return r.preferred.Hash(password)
+}
+
+// Lookup returns the hasher responsible for hashedPassword
+func (r *Registry) Lookup(hashedPassword string) (Hasher, error) {
+	if !strings.HasPrefix(hashedPassword, "$") {
+		return nil, ErrMissingPrefix
+	}
+	prefix, _, _ := strings.Cut(hashedPassword[1:], "$")
+	h, ok := r.hashers[prefix]
+	if !ok {
+		return nil, fmt.Errorf("%w: no hasher registered for %q", ErrUnsupportedAlgorithm, prefix)
+	}
+	return h, nil
+}
+
+// Verify checks password against hashedPassword using the hasher matching its
+// prefix. Hashes without a registered prefix cost as much as DummyVerify.
+func (r *Registry) Verify(hashedPassword string, password []byte) (bool, error) {
+	h, err := r.Lookup(hashedPassword)
+	if err != nil {
+		r.DummyVerify(password)
+		return false, err
+	}
+	return h.Verify(hashedPassword, password)
+}
+
+// DummyVerify burns the cost of hashing password with the preferred hasher and
+// always returns false, so unknown accounts take as long to reject as known ones
+func (r *Registry) DummyVerify(password []byte) bool {
+	_, _ = r.preferred.Hash(password)
+	return false
+}
+
+// NeedsRehash reports whether hashedPassword was not created by the preferred
+// hasher or was created by it with weaker settings
+func (r *Registry) NeedsRehash(hashedPassword string) (bool, error) {
+	h, err := r.Lookup(hashedPassword)
+	if err != nil {
+		return false, err
+	}
+	if h != r.preferred {
+		return true, nil
+	}
+	if checker, ok := h.(RehashChecker); ok {
+		return checker.NeedsRehash(hashedPassword)
+	}
+	return false, nil
+}
+
+// Hash hashes password with the preferred hasher of DefaultRegistry
+func Hash(password []byte) (string, error) {
+	return DefaultRegistry.Hash(password)
+}
+
+// HashString hashes password with the preferred hasher of DefaultRegistry
+func HashString(password string) (string, error) {
+	return Hash([]byte(password))
+}
+
+// Verify checks password against hashedPassword using DefaultRegistry
+func Verify(hashedPassword string, password []byte) (bool, error) {
+	return DefaultRegistry.Verify(hashedPassword, password)
+}
+
+// VerifyString checks password against hashedPassword using DefaultRegistry
+func VerifyString(hashedPassword, password string) (bool, error) {
+	return Verify(hashedPassword, []byte(password))
+}
+
+// DummyVerify burns the cost of a DefaultRegistry verification and always returns false
+func DummyVerify(password []byte) bool {
+	return DefaultRegistry.DummyVerify(password)
+}
+
+// NeedsRehash reports whether hashedPassword should be replaced by a hash of DefaultRegistry's preferred hasher
+func NeedsRehash(hashedPassword string) (bool, error) {
+	return DefaultRegistry.NeedsRehash(hashedPassword)
+}
+
+// Argon2idHasher hashes passwords with Argon2id. Zero Params use the value of
+// Argon2idDefaultParams at the time of each call.
+type Argon2idHasher struct {
+	Params Argon2idParams
+}
+
+func NewArgon2idHasher(p Argon2idParams) *Argon2idHasher {
+	return &Argon2idHasher{Params: p}
+}
+
+func (h *Argon2idHasher) Prefixes() []string {
+	return []string{"argon2id"}
+}
+
+func (h *Argon2idHasher) Hash(password []byte) (string, error) {
+	salt, err := generateArgon2idSalt()
+	if err != nil {
+		return "", err
+	}
+	return NewArgon2idHash(password, salt, h.params()).String(), nil
+}
+
+func (h *Argon2idHasher) Verify(hashedPassword string, password []byte) (bool, error) {
+	parsed, err := ParseArgon2id(hashedPassword)
+	if err != nil {
+		DummyVerifyArgon2idWithParams(password, h.params())
+		return false, err
+	}
+	return parsed.Verify(password)
+}
+
+func (h *Argon2idHasher) NeedsRehash(hashedPassword string) (bool, error) {
+	return Argon2idNeedsRehash(hashedPassword, h.params())
+}
+
+func (h *Argon2idHasher) params() Argon2idParams {
+	return paramsOrDefault(h.Params)
+}
+
+// paramsOrDefault returns p, or the current Argon2idDefaultParams if p is zero
+func paramsOrDefault(p Argon2idParams) Argon2idParams {
+	if p == (Argon2idParams{}) {
+		return Argon2idDefaultParams
+	}
+	return p
+}
+
+// Argon2iHasher hashes passwords with Argon2i, the data-independent variant of
+// Argon2. Zero Params use the value of Argon2idDefaultParams at the time of each call.
+type Argon2iHasher struct {
+	Params Argon2idParams
+}
+
+func NewArgon2iHasher(p Argon2idParams) *Argon2iHasher {
+	return &Argon2iHasher{Params: p}
+}
+
+func (h *Argon2iHasher) Prefixes() []string {
+	return []string{"argon2i"}
+}
+
+func (h *Argon2iHasher) Hash(password []byte) (string, error) {
+	salt, err := generateArgon2iSalt()
+	if err != nil {
+		return "", err
+	}
+	return NewArgon2iHash(password, salt, h.params()).String(), nil
+}
+
+func (h *Argon2iHasher) Verify(hashedPassword string, password []byte) (bool, error) {
+	parsed, err := ParseArgon2i(hashedPassword)
+	if err != nil {
+		DummyVerifyArgon2idWithParams(password, h.params())
+		return false, err
+	}
+	return parsed.Verify(password), nil
+}
+
+func (h *Argon2iHasher) params() Argon2idParams {
+	return paramsOrDefault(h.Params)
+}
+
+const Argon2iSaltLen = 16
+
+func generateArgon2iSalt() ([]byte, error) {
+	salt := make([]byte, Argon2iSaltLen)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// Argon2iHash is a parsed $argon2i$ hash, kept for verifying legacy hashes
+type Argon2iHash struct {
+	Params Argon2idParams
+	Salt   []byte
+	Hash   []byte
+}
+
+func NewArgon2iHash(password, salt []byte, p Argon2idParams) Argon2iHash {
+	return Argon2iHash{
+		Params: p,
+		Salt:   salt,
+		Hash:   argon2.Key(password, salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLen),
+	}
+}
+
+// ParseArgon2i parses an $argon2i$ hash, peppered hashes and associated data are not supported
+func ParseArgon2i(hashedPassword string) (Argon2iHash, error) {
+	h, err := parseArgon2("argon2i", hashedPassword)
+	if err != nil {
+		return Argon2iHash{}, err
+	}
+	if h.KeyID != nil || h.Data != nil {
+		return Argon2iHash{}, fmt.Errorf("%w: argon2i does not support keyid or data", ErrMalformedParams)
+	}
+	return Argon2iHash{Params: h.Params, Salt: h.Salt, Hash: h.Hash}, nil
+}
+
+func (h Argon2iHash) PHC() PHC {
+	return PHC{
+		ID:      "argon2i",
+		Version: argon2idVersion,
+		Params: []PHCParam{
+			{Key: "m", Value: strconv.FormatUint(uint64(h.Params.Memory), 10)},
+			{Key: "t", Value: strconv.FormatUint(uint64(h.Params.Iterations), 10)},
+			{Key: "p", Value: strconv.FormatUint(uint64(h.Params.Parallelism), 10)},
+		},
+		Salt: h.Salt,
+		Hash: h.Hash,
+	}
+}
+
+func (h Argon2iHash) String() string {
+	return h.PHC().String()
+}
+
+func (h Argon2iHash) Verify(password []byte) bool {
+	p := h.Params
+	computed := argon2.Key(password, h.Salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(h.Hash)))
+	return equalHashes(h.Hash, computed)
+}
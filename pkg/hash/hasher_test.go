@@ -0,0 +1,165 @@
+package hash
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+var testArgon2idParams = Argon2idParams{
+	Memory:      64,
+	Iterations:  1,
+	Parallelism: 1,
+	KeyLen:      32,
+}
+
+func newTestRegistry() *Registry {
+	return NewRegistry(
+		NewArgon2idHasher(testArgon2idParams),
+		NewArgon2iHasher(testArgon2idParams),
+		NewBcryptHasher(4),
+		NewScryptHasher(ScryptParams{LogN: 4, R: 8, P: 1, KeyLen: 32}),
+		NewPBKDF2Hasher(PBKDF2Params{Iterations: 1000, KeyLen: 32}),
+	)
+}
+
+func TestRegistryHashUsesPreferred(t *testing.T) {
+	i := is.New(t)
+	r := newTestRegistry()
+
+	hashed, err := r.Hash([]byte("password"))
+	i.NoErr(err)
+	i.True(strings.HasPrefix(hashed, "$argon2id$"))
+
+	needsRehash, err := r.NeedsRehash(hashed)
+	i.NoErr(err)
+	i.True(!needsRehash)
+}
+
+func TestRegistryVerifyDispatch(t *testing.T) {
+	r := newTestRegistry()
+	hashers := []Hasher{
+		NewArgon2idHasher(testArgon2idParams),
+		NewArgon2iHasher(testArgon2idParams),
+		NewBcryptHasher(4),
+		NewScryptHasher(ScryptParams{LogN: 4, R: 8, P: 1, KeyLen: 32}),
+		NewPBKDF2Hasher(PBKDF2Params{Iterations: 1000, KeyLen: 32}),
+	}
+
+	for _, h := range hashers {
+		t.Run(h.Prefixes()[0], func(t *testing.T) {
+			is := is.New(t)
+			hashed, err := h.Hash([]byte("password"))
+			is.NoErr(err)
+
+			match, err := r.Verify(hashed, []byte("password"))
+			is.NoErr(err)
+			is.True(match)
+
+			match, err = r.Verify(hashed, []byte("wrongpassword"))
+			is.NoErr(err)
+			is.True(!match)
+
+			needsRehash, err := r.NeedsRehash(hashed)
+			is.NoErr(err)
+			is.Equal(needsRehash, h.Prefixes()[0] != "argon2id")
+		})
+	}
+}
+
+func TestParseArgon2i(t *testing.T) {
+	i := is.New(t)
+	hashed, err := NewArgon2iHasher(testArgon2idParams).Hash([]byte("password"))
+	i.NoErr(err)
+	i.True(strings.HasPrefix(hashed, "$argon2i$v=19$m=64,t=1,p=1$"))
+
+	h, err := ParseArgon2i(hashed)
+	i.NoErr(err)
+	i.Equal(h.Params.Memory, testArgon2idParams.Memory)
+	i.Equal(len(h.Salt), Argon2iSaltLen)
+	i.Equal(h.String(), hashed)
+	i.True(h.Verify([]byte("password")))
+	i.True(!h.Verify([]byte("wrongpassword")))
+
+	_, err = ParseArgon2i(strings.Replace(hashed, "$argon2i$", "$argon2id$", 1))
+	i.True(errors.Is(err, ErrUnsupportedAlgorithm))
+	_, err = ParseArgon2i(strings.Replace(hashed, "p=1$", "p=1,keyid=azE$", 1))
+	i.True(errors.Is(err, ErrMalformedParams))
+}
+
+func TestRegistryVerifyLegacyHashes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		hash     string
+		password string
+	}{
+		{
+			name:     "bcrypt",
+			hash:     "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW",
+			password: "U*U",
+		},
+		{
+			name:     "pbkdf2-sha256",
+			hash:     "$pbkdf2-sha256$6400$0ZrzXitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M",
+			password: "password",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			match, err := newTestRegistry().Verify(tc.hash, []byte(tc.password))
+			is.NoErr(err)
+			is.True(match)
+		})
+	}
+}
+
+func TestRegistryUnknownAlgorithm(t *testing.T) {
+	i := is.New(t)
+	r := newTestRegistry()
+
+	_, err := r.Verify("$md5$c2FsdA$aGFzaA", []byte("password"))
+	i.True(errors.Is(err, ErrUnsupportedAlgorithm))
+
+	_, err = r.Verify("plaintext", []byte("password"))
+	i.True(errors.Is(err, ErrMissingPrefix))
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	i := is.New(t)
+	hashed, err := HashString("password")
+	i.NoErr(err)
+	i.True(strings.HasPrefix(hashed, "$argon2id$v=19$m=32768,t=3,p=4$"))
+
+	match, err := VerifyString(hashed, "password")
+	i.NoErr(err)
+	i.True(match)
+
+	needsRehash, err := NeedsRehash(hashed)
+	i.NoErr(err)
+	i.True(!needsRehash)
+}
+
+func TestDefaultRegistryFollowsDefaultParams(t *testing.T) {
+	i := is.New(t)
+	old := Argon2idDefaultParams
+	t.Cleanup(func() { Argon2idDefaultParams = old })
+	hashed, err := HashString("password")
+	i.NoErr(err)
+
+	Argon2idDefaultParams = Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, KeyLen: 32}
+	needsRehash, err := NeedsRehash(hashed)
+	i.NoErr(err)
+	i.True(!needsRehash)
+
+	Argon2idDefaultParams.Iterations = 4
+	needsRehash, err = NeedsRehash(hashed)
+	i.NoErr(err)
+	i.True(needsRehash)
+	rehashed, err := HashString("password")
+	i.NoErr(err)
+	i.True(strings.HasPrefix(rehashed, "$argon2id$v=19$m=64,t=4,p=1$"))
+}
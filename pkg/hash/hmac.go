@@ -0,0 +1,78 @@
+package hash
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	stdhash "hash"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+type MACAlgorithm string
+
+const (
+	HMACSHA256 MACAlgorithm = "hmac-sha256"
+	HMACSHA384 MACAlgorithm = "hmac-sha384"
+	HMACSHA512 MACAlgorithm = "hmac-sha512"
+	BLAKE2b256 MACAlgorithm = "blake2b-256"
+	BLAKE2b512 MACAlgorithm = "blake2b-512"
+)
+
+var (
+	ErrUnsupportedMAC = errors.New("unsupported mac algorithm")
+	ErrEmptyMACKey    = errors.New("invalid mac key: must not be empty")
+)
+
+// NewMAC returns a keyed hash for alg. Keys must not be empty, since unkeyed
+// BLAKE2b is a plain hash, and BLAKE2b keys must not exceed 64 bytes.
+func NewMAC(alg MACAlgorithm, key []byte) (stdhash.Hash, error) {
+	if len(key) == 0 {
+		return nil, ErrEmptyMACKey
+	}
+	switch alg {
+	case HMACSHA256:
+		return hmac.New(sha256.New, key), nil
+	case HMACSHA384:
+		return hmac.New(sha512.New384, key), nil
+	case HMACSHA512:
+		return hmac.New(sha512.New, key), nil
+	case BLAKE2b256:
+		return blake2b.New256(key)
+	case BLAKE2b512:
+		return blake2b.New512(key)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMAC, alg)
+	}
+}
+
+// MAC computes the message authentication code of message under key
+func MAC(alg MACAlgorithm, key, message []byte) ([]byte, error) {
+	mac, err := NewMAC(alg, key)
+	if err != nil {
+		return nil, err
+	}
+	mac.Write(message)
+	return mac.Sum(nil), nil
+}
+
+// MACString is like MAC for string messages
+func MACString(alg MACAlgorithm, key []byte, message string) ([]byte, error) {
+	return MAC(alg, key, []byte(message))
+}
+
+// VerifyMAC reports in constant time whether mac authenticates message under key
+func VerifyMAC(alg MACAlgorithm, key, message, mac []byte) (bool, error) {
+	expected, err := MAC(alg, key, message)
+	if err != nil {
+		return false, err
+	}
+	return equalHashes(expected, mac), nil
+}
+
+// VerifyMACString is like VerifyMAC for string messages
+func VerifyMACString(alg MACAlgorithm, key []byte, message string, mac []byte) (bool, error) {
+	return VerifyMAC(alg, key, []byte(message), mac)
+}
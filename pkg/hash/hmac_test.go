@@ -0,0 +1,68 @@
+package hash
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestMACVectors(t *testing.T) {
+	// RFC 4231 test case 2
+	testCases := []struct {
+		alg      MACAlgorithm
+		expected string
+	}{
+		{alg: HMACSHA256, expected: "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"},
+		{alg: HMACSHA384, expected: "af45d2e376484031617f78d2b58a6b1b9c7ef464f5a01b47e42ec3736322445e8e2240ca5e69e2c78b3239ecfab21649"},
+		{alg: HMACSHA512, expected: "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.alg), func(t *testing.T) {
+			is := is.New(t)
+			mac, err := MACString(tc.alg, []byte("Jefe"), "what do ya want for nothing?")
+			is.NoErr(err)
+			is.Equal(hex.EncodeToString(mac), tc.expected)
+		})
+	}
+}
+
+func TestVerifyMAC(t *testing.T) {
+	for _, alg := range []MACAlgorithm{HMACSHA256, HMACSHA384, HMACSHA512, BLAKE2b256, BLAKE2b512} {
+		t.Run(string(alg), func(t *testing.T) {
+			is := is.New(t)
+			key := []byte("secret")
+			mac, err := MACString(alg, key, "message")
+			is.NoErr(err)
+
+			valid, err := VerifyMACString(alg, key, "message", mac)
+			is.NoErr(err)
+			is.True(valid)
+
+			valid, err = VerifyMACString(alg, key, "tampered", mac)
+			is.NoErr(err)
+			is.True(!valid)
+
+			valid, err = VerifyMACString(alg, []byte("other"), "message", mac)
+			is.NoErr(err)
+			is.True(!valid)
+		})
+	}
+}
+
+func TestMACErrors(t *testing.T) {
+	i := is.New(t)
+
+	_, err := MACString("md5", []byte("secret"), "message")
+	i.True(errors.Is(err, ErrUnsupportedMAC))
+
+	_, err = MACString(BLAKE2b256, make([]byte, 65), "message")
+	i.True(err != nil)
+
+	for _, alg := range []MACAlgorithm{HMACSHA256, BLAKE2b256} {
+		_, err = MACString(alg, nil, "message")
+		i.True(errors.Is(err, ErrEmptyMACKey))
+	}
+}
@@ -0,0 +1,194 @@
+package hash
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/scrypt"
+)
+
+type KDFAlgorithm string
+
+const (
+	KDFArgon2id KDFAlgorithm = "argon2id"
+	KDFScrypt   KDFAlgorithm = "scrypt"
+)
+
+var (
+	ErrInvalidKeyLength = errors.New("invalid key length")
+	ErrDuplicateLabel   = errors.New("duplicate sub-key label")
+)
+
+// HKDFExtract derives a pseudorandom key from secret and salt using HKDF-SHA256
+func HKDFExtract(secret, salt []byte) []byte {
+	return hkdf.Extract(sha256.New, secret, salt)
+}
+
+// HKDFExpand expands a pseudorandom key into length bytes bound to info using HKDF-SHA256
+func HKDFExpand(prk, info []byte, length int) ([]byte, error) {
+	if length <= 0 || length > 255*sha256.Size {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidKeyLength, length)
+	}
+	key := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, info), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// HKDF derives length bytes from secret, salt and info using HKDF-SHA256
+func HKDF(secret, salt, info []byte, length int) ([]byte, error) {
+	return HKDFExpand(HKDFExtract(secret, salt), info, length)
+}
+
+// DeriveSubKey derives a length byte sub-key from master, domain separated by label
+func DeriveSubKey(master, salt []byte, label string, length int) ([]byte, error) {
+	return HKDF(master, salt, []byte(label), length)
+}
+
+// DeriveSubKeys derives one length byte sub-key per label from master, each domain separated by its label
+func DeriveSubKeys(master, salt []byte, length int, labels ...string) (map[string][]byte, error) {
+	prk := HKDFExtract(master, salt)
+	keys := make(map[string][]byte, len(labels))
+	for _, label := range labels {
+		if _, ok := keys[label]; ok {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateLabel, label)
+		}
+		key, err := HKDFExpand(prk, []byte(label), length)
+		if err != nil {
+			return nil, err
+		}
+		keys[label] = key
+	}
+	return keys, nil
+}
+
+// KeyDerivation describes how a key is derived from a passphrase: the password
+// based KDF, its parameters and the salt. It serializes to a PHC string without
+// hash, e.g. $argon2id$v=19$m=65536,t=3,p=4$salt, which can be stored next to
+// the ciphertext so the key can be derived again.
+type KeyDerivation struct {
+	Algorithm KDFAlgorithm
+	Argon2id  Argon2idParams
+	Scrypt    ScryptParams
+	Salt      []byte
+}
+
+// NewArgon2idKeyDerivation creates an Argon2id key derivation with a random salt
+func NewArgon2idKeyDerivation(p Argon2idParams) (KeyDerivation, error) {
+	salt, err := generateArgon2idSalt()
+	if err != nil {
+		return KeyDerivation{}, err
+	}
+	return KeyDerivation{Algorithm: KDFArgon2id, Argon2id: p, Salt: salt}, nil
+}
+
+// NewScryptKeyDerivation creates a scrypt key derivation with a random salt
+func NewScryptKeyDerivation(p ScryptParams) (KeyDerivation, error) {
+	salt, err := generateScryptSalt()
+	if err != nil {
+		return KeyDerivation{}, err
+	}
+	return KeyDerivation{Algorithm: KDFScrypt, Scrypt: p, Salt: salt}, nil
+}
+
+// ParseKeyDerivation parses a key derivation formatted by KeyDerivation.String
+func ParseKeyDerivation(s string) (KeyDerivation, error) {
+	phc, err := ParsePHC(s)
+	if err != nil {
+		return KeyDerivation{}, err
+	}
+	if phc.Hash != nil {
+		return KeyDerivation{}, fmt.Errorf("%w: key derivations carry no hash", ErrInvalidFieldCount)
+	}
+	if len(phc.Salt) == 0 {
+		return KeyDerivation{}, ErrEmptySalt
+	}
+
+	d := KeyDerivation{Algorithm: KDFAlgorithm(phc.ID), Salt: phc.Salt}
+	switch d.Algorithm {
+	case KDFArgon2id:
+		if phc.Version != argon2idVersion {
+			return KeyDerivation{}, fmt.Errorf("%w: expected v=%d, got v=%d", ErrUnsupportedVersion, argon2idVersion, phc.Version)
+		}
+		h, err := parseArgon2idParams(phc.Params)
+		if err != nil {
+			return KeyDerivation{}, err
+		}
+		d.Argon2id = h.Params
+	case KDFScrypt:
+		d.Scrypt, err = parseScryptParams(phc.Params)
+		if err != nil {
+			return KeyDerivation{}, err
+		}
+	default:
+		return KeyDerivation{}, fmt.Errorf("%w: expected argon2id or scrypt, got %s", ErrUnsupportedAlgorithm, phc.ID)
+	}
+	return d, nil
+}
+
+// String formats d as a PHC string without hash
+func (d KeyDerivation) String() string {
+	switch d.Algorithm {
+	case KDFArgon2id:
+		phc := Argon2idHash{Params: d.Argon2id}.PHC()
+		phc.Salt = d.Salt
+		return phc.String()
+	case KDFScrypt:
+		return PHC{ID: string(KDFScrypt), Params: formatScryptParams(d.Scrypt), Salt: d.Salt}.String()
+	default:
+		return PHC{ID: string(d.Algorithm), Salt: d.Salt}.String()
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler so key derivations can be embedded in JSON
+func (d KeyDerivation) MarshalText() ([]byte, error) {
+	if d.Algorithm != KDFArgon2id && d.Algorithm != KDFScrypt {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, d.Algorithm)
+	}
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *KeyDerivation) UnmarshalText(text []byte) error {
+	parsed, err := ParseKeyDerivation(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// DeriveKey derives a length byte key from passphrase
+func (d KeyDerivation) DeriveKey(passphrase []byte, length int) ([]byte, error) {
+	if length <= 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidKeyLength, length)
+	}
+	switch d.Algorithm {
+	case KDFArgon2id:
+		p := d.Argon2id
+		if p.Memory == 0 || p.Iterations == 0 || p.Parallelism == 0 {
+			return nil, fmt.Errorf("%w: values must be greater than 0", ErrInvalidParams)
+		}
+		p.KeyLen = uint32(length)
+		return Argon2idBytesWithParams(passphrase, d.Salt, p), nil
+	case KDFScrypt:
+		p := d.Scrypt
+		return scrypt.Key(passphrase, d.Salt, 1<<p.LogN, p.R, p.P, length)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, d.Algorithm)
+	}
+}
+
+// DeriveSubKeys derives a master key from passphrase and expands it into one
+// length byte sub-key per label with HKDF
+func (d KeyDerivation) DeriveSubKeys(passphrase []byte, length int, labels ...string) (map[string][]byte, error) {
+	master, err := d.DeriveKey(passphrase, sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+	return DeriveSubKeys(master, nil, length, labels...)
+}
@@ -0,0 +1,143 @@
+package hash
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestHKDFVector(t *testing.T) {
+	// RFC 5869 test case 1
+	i := is.New(t)
+	ikm := bytes.Repeat([]byte{0x0b}, 22)
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+
+	prk := HKDFExtract(ikm, salt)
+	i.Equal(hex.EncodeToString(prk), "077709362c2e32df0ddc3f0dc47bba6390b6c73bb50f9c3122ec844ad7c2b3e5")
+
+	okm, err := HKDFExpand(prk, info, 42)
+	i.NoErr(err)
+	i.Equal(hex.EncodeToString(okm), "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865")
+
+	okm2, err := HKDF(ikm, salt, info, 42)
+	i.NoErr(err)
+	i.Equal(okm2, okm)
+
+	_, err = HKDFExpand(prk, info, 255*32+1)
+	i.True(errors.Is(err, ErrInvalidKeyLength))
+}
+
+func TestDeriveSubKeys(t *testing.T) {
+	i := is.New(t)
+	master := []byte("master secret")
+
+	keys, err := DeriveSubKeys(master, nil, 32, "encryption", "authentication")
+	i.NoErr(err)
+	i.Equal(len(keys), 2)
+	i.Equal(len(keys["encryption"]), 32)
+	i.True(!bytes.Equal(keys["encryption"], keys["authentication"]))
+
+	single, err := DeriveSubKey(master, nil, "encryption", 32)
+	i.NoErr(err)
+	i.Equal(single, keys["encryption"])
+
+	_, err = DeriveSubKeys(master, nil, 32, "a", "a")
+	i.True(errors.Is(err, ErrDuplicateLabel))
+}
+
+func TestKeyDerivationRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name   string
+		create func() (KeyDerivation, error)
+		prefix string
+	}{
+		{
+			name:   "argon2id",
+			create: func() (KeyDerivation, error) { return NewArgon2idKeyDerivation(testArgon2idParams) },
+			prefix: "$argon2id$v=19$m=64,t=1,p=1$",
+		},
+		{
+			name:   "scrypt",
+			create: func() (KeyDerivation, error) { return NewScryptKeyDerivation(ScryptParams{LogN: 4, R: 8, P: 1}) },
+			prefix: "$scrypt$ln=4,r=8,p=1$",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			d, err := tc.create()
+			is.NoErr(err)
+			is.Equal(len(d.Salt), Argon2idSaltLen)
+
+			encoded := d.String()
+			is.True(strings.HasPrefix(encoded, tc.prefix))
+			is.Equal(strings.Count(encoded, "$"), strings.Count(tc.prefix, "$"))
+
+			parsed, err := ParseKeyDerivation(encoded)
+			is.NoErr(err)
+
+			key1, err := d.DeriveKey([]byte("passphrase"), 32)
+			is.NoErr(err)
+			key2, err := parsed.DeriveKey([]byte("passphrase"), 32)
+			is.NoErr(err)
+			is.Equal(key1, key2)
+
+			other, err := parsed.DeriveKey([]byte("other"), 32)
+			is.NoErr(err)
+			is.True(!bytes.Equal(key1, other))
+
+			keys, err := parsed.DeriveSubKeys([]byte("passphrase"), 16, "enc", "mac")
+			is.NoErr(err)
+			is.Equal(len(keys["enc"]), 16)
+			is.True(!bytes.Equal(keys["enc"], keys["mac"]))
+		})
+	}
+}
+
+func TestKeyDerivationJSON(t *testing.T) {
+	i := is.New(t)
+	d, err := NewArgon2idKeyDerivation(testArgon2idParams)
+	i.NoErr(err)
+
+	type envelope struct {
+		KDF KeyDerivation `json:"kdf"`
+	}
+	data, err := json.Marshal(envelope{KDF: d})
+	i.NoErr(err)
+	i.True(strings.Contains(string(data), `"kdf":"$argon2id$v=19$m=64,t=1,p=1$`))
+
+	var decoded envelope
+	i.NoErr(json.Unmarshal(data, &decoded))
+	i.Equal(decoded.KDF.Algorithm, KDFArgon2id)
+	i.Equal(decoded.KDF.Salt, d.Salt)
+	i.Equal(decoded.KDF.Argon2id.Memory, testArgon2idParams.Memory)
+}
+
+func TestParseKeyDerivationErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected error
+	}{
+		{name: "hash present", input: "$argon2id$v=19$m=64,t=1,p=1$c2FsdA$aGFzaA", expected: ErrInvalidFieldCount},
+		{name: "no salt", input: "$argon2id$v=19$m=64,t=1,p=1", expected: ErrEmptySalt},
+		{name: "version", input: "$argon2id$m=64,t=1,p=1$c2FsdA", expected: ErrUnsupportedVersion},
+		{name: "algorithm", input: "$bcrypt$c2FsdA", expected: ErrUnsupportedAlgorithm},
+		{name: "scrypt params", input: "$scrypt$ln=4$c2FsdA", expected: ErrMalformedParams},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			_, err := ParseKeyDerivation(tc.input)
+			is.True(errors.Is(err, tc.expected))
+		})
+	}
+}
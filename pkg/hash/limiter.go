@@ -0,0 +1,186 @@
+package hash
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrExceedsMemoryBudget = errors.New("argon2id memory exceeds limiter budget")
+
+// Limiter caps the number of concurrent Argon2id computations and the total
+// memory they may allocate. Waiters are served in FIFO order.
+type Limiter struct {
+	maxConcurrent int
+	maxMemory     uint64
+
+	mu           sync.Mutex
+	active       int
+	activeMemory uint64
+	waiters      list.List
+	acquired     uint64
+	canceled     uint64
+}
+
+type limiterWaiter struct {
+	memory uint64
+	ready  chan struct{}
+}
+
+// LimiterStats is a snapshot of a Limiter's usage
+type LimiterStats struct {
+	MaxConcurrent int
+	MaxMemory     uint64
+	Active        int
+	ActiveMemory  uint64
+	Waiting       int
+	Acquired      uint64
+	Canceled      uint64
+}
+
+// NewLimiter creates a limiter allowing at most maxConcurrent computations
+// using at most maxMemory KiB in total. Zero disables the respective limit.
+func NewLimiter(maxConcurrent int, maxMemory uint64) *Limiter {
+	return &Limiter{
+		maxConcurrent: maxConcurrent,
+		maxMemory:     maxMemory,
+	}
+}
+
+// Acquire blocks until a computation using memory KiB may start or ctx is done.
+// The returned function must be called once the computation finished.
+func (l *Limiter) Acquire(ctx context.Context, memory uint32) (func(), error) {
+	mem := uint64(memory)
+	if l.maxMemory > 0 && mem > l.maxMemory {
+		return nil, fmt.Errorf("%w: %d KiB requested, %d KiB allowed", ErrExceedsMemoryBudget, mem, l.maxMemory)
+	}
+
+	l.mu.Lock()
+	if l.waiters.Len() == 0 && l.fits(mem) {
+		l.grant(mem)
+		l.mu.Unlock()
+		return l.releaseFunc(mem), nil
+	}
+	w := &limiterWaiter{memory: mem, ready: make(chan struct{})}
+	elem := l.waiters.PushBack(w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return l.releaseFunc(mem), nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		select {
+		case <-w.ready:
+			// granted concurrently, give the slot back
+			l.release(mem)
+		default:
+			l.waiters.Remove(elem)
+			l.wakeWaiters()
+		}
+		l.canceled++
+		l.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// Stats returns a snapshot of the limiter's usage
+func (l *Limiter) Stats() LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return LimiterStats{
+		MaxConcurrent: l.maxConcurrent,
+		MaxMemory:     l.maxMemory,
+		Active:        l.active,
+		ActiveMemory:  l.activeMemory,
+		Waiting:       l.waiters.Len(),
+		Acquired:      l.acquired,
+		Canceled:      l.canceled,
+	}
+}
+
+// Argon2idBytesWithParams computes Argon2idBytesWithParams once the limiter
+// admits it. If ctx is done mid computation the slot stays taken until the
+// computation actually finished.
+func (l *Limiter) Argon2idBytesWithParams(ctx context.Context, password, salt []byte, p Argon2idParams) ([]byte, error) {
+	return runContext(ctx, func() ([]byte, error) {
+		release, err := l.Acquire(ctx, p.Memory)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return Argon2idBytesWithParams(password, salt, p), nil
+	})
+}
+
+// VerifyArgon2id verifies password against hashedPassword once the limiter
+// admits it. Malformed hashes burn the cost of a default-params verification
+// through the limiter as well before their error is returned, like VerifyArgon2id.
+func (l *Limiter) VerifyArgon2id(ctx context.Context, hashedPassword string, password []byte) (bool, error) {
+	h, err := ParseArgon2id(hashedPassword)
+	if err != nil {
+		_, _ = l.run(ctx, Argon2idDefaultParams.Memory, func() (bool, error) {
+			return DummyVerifyArgon2id(password), nil
+		})
+		return false, err
+	}
+	return l.run(ctx, h.Params.Memory, func() (bool, error) {
+		return h.Verify(password)
+	})
+}
+
+// run calls fn on a worker goroutine once the limiter admits memory KiB
+func (l *Limiter) run(ctx context.Context, memory uint32, fn func() (bool, error)) (bool, error) {
+	return runContext(ctx, func() (bool, error) {
+		release, err := l.Acquire(ctx, memory)
+		if err != nil {
+			return false, err
+		}
+		defer release()
+		return fn()
+	})
+}
+
+func (l *Limiter) fits(memory uint64) bool {
+	if l.maxConcurrent > 0 && l.active >= l.maxConcurrent {
+		return false
+	}
+	return l.maxMemory == 0 || l.activeMemory+memory <= l.maxMemory
+}
+
+func (l *Limiter) grant(memory uint64) {
+	l.active++
+	l.activeMemory += memory
+	l.acquired++
+}
+
+func (l *Limiter) release(memory uint64) {
+	l.active--
+	l.activeMemory -= memory
+	l.wakeWaiters()
+}
+
+func (l *Limiter) releaseFunc(memory uint64) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.release(memory)
+			l.mu.Unlock()
+		})
+	}
+}
+
+func (l *Limiter) wakeWaiters() {
+	for front := l.waiters.Front(); front != nil; front = l.waiters.Front() {
+		w := front.Value.(*limiterWaiter)
+		if !l.fits(w.memory) {
+			return
+		}
+		l.grant(w.memory)
+		l.waiters.Remove(front)
+		close(w.ready)
+	}
+}
@@ -0,0 +1,208 @@
+package hash
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func waitForWaiters(l *Limiter, n int) {
+	for l.Stats().Waiting != n {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLimiterConcurrency(t *testing.T) {
+	i := is.New(t)
+	l := NewLimiter(2, 0)
+
+	release1, err := l.Acquire(context.Background(), 64)
+	i.NoErr(err)
+	release2, err := l.Acquire(context.Background(), 64)
+	i.NoErr(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = l.Acquire(ctx, 64)
+	i.True(errors.Is(err, context.DeadlineExceeded))
+
+	stats := l.Stats()
+	i.Equal(stats.Active, 2)
+	i.Equal(stats.ActiveMemory, uint64(128))
+	i.Equal(stats.Waiting, 0)
+	i.Equal(stats.Canceled, uint64(1))
+
+	acquired := make(chan error)
+	go func() {
+		release, err := l.Acquire(context.Background(), 64)
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+	waitForWaiters(l, 1)
+
+	release1()
+	release1()
+	i.NoErr(<-acquired)
+	release2()
+
+	stats = l.Stats()
+	i.Equal(stats.Active, 0)
+	i.Equal(stats.ActiveMemory, uint64(0))
+	i.Equal(stats.Acquired, uint64(3))
+}
+
+func TestLimiterMemoryBudget(t *testing.T) {
+	i := is.New(t)
+	l := NewLimiter(0, 100)
+
+	_, err := l.Acquire(context.Background(), 101)
+	i.True(errors.Is(err, ErrExceedsMemoryBudget))
+
+	release, err := l.Acquire(context.Background(), 60)
+	i.NoErr(err)
+
+	acquired := make(chan error)
+	go func() {
+		release, err := l.Acquire(context.Background(), 50)
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+	waitForWaiters(l, 1)
+	i.Equal(l.Stats().ActiveMemory, uint64(60))
+
+	release()
+	i.NoErr(<-acquired)
+}
+
+func TestLimiterFIFO(t *testing.T) {
+	i := is.New(t)
+	l := NewLimiter(0, 100)
+
+	release, err := l.Acquire(context.Background(), 60)
+	i.NoErr(err)
+
+	large := make(chan error)
+	go func() {
+		release, err := l.Acquire(context.Background(), 80)
+		if err == nil {
+			release()
+		}
+		large <- err
+	}()
+	waitForWaiters(l, 1)
+
+	// a small request must not overtake the queued large one
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = l.Acquire(ctx, 10)
+	i.True(errors.Is(err, context.DeadlineExceeded))
+
+	release()
+	i.NoErr(<-large)
+}
+
+func TestLimiterCanceledWaiterUnblocksQueue(t *testing.T) {
+	i := is.New(t)
+	l := NewLimiter(0, 100)
+
+	release, err := l.Acquire(context.Background(), 60)
+	i.NoErr(err)
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+	go func() {
+		_, err := l.Acquire(ctx, 80)
+		canceled <- err
+	}()
+	waitForWaiters(l, 1)
+
+	small := make(chan error)
+	go func() {
+		release, err := l.Acquire(context.Background(), 10)
+		if err == nil {
+			release()
+		}
+		small <- err
+	}()
+	waitForWaiters(l, 2)
+
+	cancel()
+	i.True(errors.Is(<-canceled, context.Canceled))
+	i.NoErr(<-small)
+}
+
+func TestLimiterArgon2id(t *testing.T) {
+	i := is.New(t)
+	l := NewLimiter(2, 256)
+	salt := []byte("0123456789abcdef")
+
+	var (
+		wg        sync.WaitGroup
+		active    atomic.Int32
+		maxActive atomic.Int32
+	)
+	original := argon2IDKey
+	argon2IDKey = func(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			current := maxActive.Load()
+			if n <= current || maxActive.CompareAndSwap(current, n) {
+				break
+			}
+		}
+		return original(password, salt, time, memory, threads, keyLen)
+	}
+	t.Cleanup(func() { argon2IDKey = original })
+
+	hashes := make([][]byte, 10)
+	errs := make([]error, len(hashes))
+	for g := range hashes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hashes[g], errs[g] = l.Argon2idBytesWithParams(context.Background(), []byte("password"), salt, testArgon2idParams)
+		}()
+	}
+	wg.Wait()
+	for g := range hashes {
+		i.NoErr(errs[g])
+		i.Equal(len(hashes[g]), 32)
+	}
+	i.True(maxActive.Load() <= 2)
+
+	hashed := Argon2idStringToStringWithParams("password", salt, testArgon2idParams)
+	match, err := l.VerifyArgon2id(context.Background(), hashed, []byte("password"))
+	i.NoErr(err)
+	i.True(match)
+	i.Equal(l.Stats().Acquired, uint64(11))
+}
+
+func TestLimiterVerifyArgon2idMalformed(t *testing.T) {
+	i := is.New(t)
+	l := NewLimiter(1, 0)
+
+	var calls atomic.Int32
+	original := argon2IDKey
+	argon2IDKey = func(password, salt []byte, time, memory uint32, threads uint8, keyLen uint32) []byte {
+		calls.Add(1)
+		return original(password, salt, 1, 64, 1, keyLen)
+	}
+	t.Cleanup(func() { argon2IDKey = original })
+
+	// malformed hashes still cost one derivation, admitted by the limiter
+	_, err := l.VerifyArgon2id(context.Background(), "$argon2id$garbage", []byte("password"))
+	i.True(errors.Is(err, ErrInvalidFieldCount))
+	i.Equal(calls.Load(), int32(1))
+	i.Equal(l.Stats().Acquired, uint64(1))
+}
@@ -0,0 +1,140 @@
+package hash
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	stdhash "hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// ab64Encoding is the adapted base64 alphabet used by passlib's PBKDF2 hashes
+var ab64Encoding = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789./").WithPadding(base64.NoPadding)
+
+const PBKDF2SaltLen = 16
+
+// maxPBKDF2Iterations bounds the rounds accepted from stored hashes, so a
+// tampered hash cannot make Verify spin for minutes
+const maxPBKDF2Iterations = 1 << 24
+
+func generatePBKDF2Salt() ([]byte, error) {
+	salt := make([]byte, PBKDF2SaltLen)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+type PBKDF2Params struct {
+	Iterations int
+	KeyLen     int
+}
+
+var PBKDF2DefaultParams = PBKDF2Params{
+	Iterations: 600_000,
+	KeyLen:     32,
+}
+
+// PBKDF2Hasher hashes passwords with PBKDF2-SHA256 into passlib compatible
+// $pbkdf2-sha256$rounds$salt$hash strings and also verifies $pbkdf2-sha512$ hashes
+type PBKDF2Hasher struct {
+	Params PBKDF2Params
+}
+
+func NewPBKDF2Hasher(p PBKDF2Params) *PBKDF2Hasher {
+	return &PBKDF2Hasher{Params: p}
+}
+
+func (h *PBKDF2Hasher) Prefixes() []string {
+	return []string{"pbkdf2-sha256", "pbkdf2-sha512"}
+}
+
+func (h *PBKDF2Hasher) Hash(password []byte) (string, error) {
+	salt, err := generatePBKDF2Salt()
+	if err != nil {
+		return "", err
+	}
+	hash := pbkdf2.Key(password, salt, h.Params.Iterations, h.Params.KeyLen, sha256.New)
+	return fmt.Sprintf("$pbkdf2-sha256$%d$%s$%s",
+		h.Params.Iterations,
+		ab64Encoding.EncodeToString(salt),
+		ab64Encoding.EncodeToString(hash)), nil
+}
+
+func (h *PBKDF2Hasher) Verify(hashedPassword string, password []byte) (bool, error) {
+	parsed, err := parsePBKDF2(hashedPassword)
+	if err != nil {
+		return false, err
+	}
+	computed := pbkdf2.Key(password, parsed.salt, parsed.iterations, len(parsed.hash), parsed.digest)
+	return equalHashes(parsed.hash, computed), nil
+}
+
+func (h *PBKDF2Hasher) NeedsRehash(hashedPassword string) (bool, error) {
+	parsed, err := parsePBKDF2(hashedPassword)
+	if err != nil {
+		return false, err
+	}
+	return parsed.iterations < h.Params.Iterations || len(parsed.hash) < h.Params.KeyLen, nil
+}
+
+type pbkdf2Hash struct {
+	digest     func() stdhash.Hash
+	iterations int
+	salt       []byte
+	hash       []byte
+}
+
+func parsePBKDF2(hashedPassword string) (pbkdf2Hash, error) {
+	if !strings.HasPrefix(hashedPassword, "$") {
+		return pbkdf2Hash{}, ErrMissingPrefix
+	}
+
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 5 {
+		return pbkdf2Hash{}, fmt.Errorf("%w: expected 5 parts, got %d", ErrInvalidFieldCount, len(parts))
+	}
+
+	var parsed pbkdf2Hash
+	switch parts[1] {
+	case "pbkdf2-sha256":
+		parsed.digest = sha256.New
+	case "pbkdf2-sha512":
+		parsed.digest = sha512.New
+	default:
+		return pbkdf2Hash{}, fmt.Errorf("%w: expected pbkdf2-sha256 or pbkdf2-sha512, got %s", ErrUnsupportedAlgorithm, parts[1])
+	}
+
+	iterations, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return pbkdf2Hash{}, fmt.Errorf("%w: failed to parse rounds", ErrMalformedParams)
+	}
+	if iterations <= 0 || iterations > maxPBKDF2Iterations {
+		return pbkdf2Hash{}, fmt.Errorf("%w: rounds must be between 1 and %d", ErrInvalidParams, maxPBKDF2Iterations)
+	}
+	parsed.iterations = iterations
+
+	parsed.salt, err = ab64Encoding.DecodeString(parts[3])
+	if err != nil {
+		return pbkdf2Hash{}, fmt.Errorf("%w in salt: %v", ErrIllegalBase64, err)
+	}
+	if len(parsed.salt) == 0 {
+		return pbkdf2Hash{}, ErrEmptySalt
+	}
+
+	parsed.hash, err = ab64Encoding.DecodeString(parts[4])
+	if err != nil {
+		return pbkdf2Hash{}, fmt.Errorf("%w in hash: %v", ErrIllegalBase64, err)
+	}
+	if len(parsed.hash) == 0 {
+		return pbkdf2Hash{}, ErrEmptyHash
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,65 @@
+package hash
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestPBKDF2Hasher(t *testing.T) {
+	i := is.New(t)
+	h := NewPBKDF2Hasher(PBKDF2Params{Iterations: 1000, KeyLen: 32})
+
+	hashed, err := h.Hash([]byte("password"))
+	i.NoErr(err)
+	i.True(strings.HasPrefix(hashed, "$pbkdf2-sha256$1000$"))
+
+	match, err := h.Verify(hashed, []byte("password"))
+	i.NoErr(err)
+	i.True(match)
+
+	match, err = h.Verify(hashed, []byte("wrongpassword"))
+	i.NoErr(err)
+	i.True(!match)
+
+	needsRehash, err := NewPBKDF2Hasher(PBKDF2Params{Iterations: 2000, KeyLen: 32}).NeedsRehash(hashed)
+	i.NoErr(err)
+	i.True(needsRehash)
+}
+
+func TestPBKDF2HasherPasslibVector(t *testing.T) {
+	i := is.New(t)
+	match, err := NewPBKDF2Hasher(PBKDF2DefaultParams).Verify(
+		"$pbkdf2-sha256$6400$0ZrzXitFSGltTQnBWOsdAw$Y11AchqV4b0sUisdZd0Xr97KWoymNE0LNNrnEgY4H9M",
+		[]byte("password"),
+	)
+	i.NoErr(err)
+	i.True(match)
+}
+
+func TestPBKDF2HasherInvalidFormat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		hash     string
+		expected error
+	}{
+		{name: "no prefix", hash: "pbkdf2-sha256", expected: ErrMissingPrefix},
+		{name: "field count", hash: "$pbkdf2-sha256$1000$c2FsdA", expected: ErrInvalidFieldCount},
+		{name: "algorithm", hash: "$pbkdf2-md5$1000$c2FsdA$aGFzaA", expected: ErrUnsupportedAlgorithm},
+		{name: "rounds", hash: "$pbkdf2-sha256$many$c2FsdA$aGFzaA", expected: ErrMalformedParams},
+		{name: "zero rounds", hash: "$pbkdf2-sha256$0$c2FsdA$aGFzaA", expected: ErrInvalidParams},
+		{name: "too many rounds", hash: "$pbkdf2-sha256$2000000000$c2FsdA$aGFzaA", expected: ErrInvalidParams},
+		{name: "salt", hash: "$pbkdf2-sha256$1000$$aGFzaA", expected: ErrEmptySalt},
+		{name: "base64", hash: "$pbkdf2-sha256$1000$c2FsdA$+++", expected: ErrIllegalBase64},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			_, err := NewPBKDF2Hasher(PBKDF2DefaultParams).Verify(tc.hash, []byte("password"))
+			is.True(errors.Is(err, tc.expected))
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package hash
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestNewKeyringInvalid(t *testing.T) {
+	i := is.New(t)
+
+	_, err := NewKeyring(Pepper{ID: "", Key: []byte("secret")})
+	i.True(errors.Is(err, ErrInvalidPepper))
+
+	_, err = NewKeyring(Pepper{ID: "k1", Key: nil})
+	i.True(errors.Is(err, ErrInvalidPepper))
+
+	_, err = NewKeyring(Pepper{ID: "k1", Key: []byte("a")}, Pepper{ID: "k1", Key: []byte("b")})
+	i.True(errors.Is(err, ErrDuplicateKey))
+}
+
+func TestPepperedArgon2idHasher(t *testing.T) {
+	i := is.New(t)
+	k, err := NewKeyring(Pepper{ID: "k1", Key: []byte("secret-1")})
+	i.NoErr(err)
+	h := NewPepperedArgon2idHasher(k, testArgon2idParams)
+
+	hashed, err := h.Hash([]byte("password"))
+	i.NoErr(err)
+	i.True(strings.Contains(hashed, ",keyid=azE$"))
+
+	match, err := h.Verify(hashed, []byte("password"))
+	i.NoErr(err)
+	i.True(match)
+
+	match, err = h.Verify(hashed, []byte("wrongpassword"))
+	i.NoErr(err)
+	i.True(!match)
+
+	// without the pepper the stored hash is useless, which is reported rather than looking like a wrong password
+	match, err = NewArgon2idHasher(testArgon2idParams).Verify(hashed, []byte("password"))
+	i.True(errors.Is(err, ErrPepperRequired))
+	i.True(!match)
+	_, err = VerifyArgon2id(hashed, []byte("password"))
+	i.True(errors.Is(err, ErrPepperRequired))
+}
+
+func TestPepperedArgon2idHasherRotation(t *testing.T) {
+	i := is.New(t)
+	oldKeyring, err := NewKeyring(Pepper{ID: "k1", Key: []byte("secret-1")})
+	i.NoErr(err)
+	oldHash, err := NewPepperedArgon2idHasher(oldKeyring, testArgon2idParams).Hash([]byte("password"))
+	i.NoErr(err)
+
+	newKeyring, err := NewKeyring(Pepper{ID: "k2", Key: []byte("secret-2")}, Pepper{ID: "k1", Key: []byte("secret-1")})
+	i.NoErr(err)
+	h := NewPepperedArgon2idHasher(newKeyring, testArgon2idParams)
+
+	match, err := h.Verify(oldHash, []byte("password"))
+	i.NoErr(err)
+	i.True(match)
+
+	needsRehash, err := h.NeedsRehash(oldHash)
+	i.NoErr(err)
+	i.True(needsRehash)
+
+	newHash, err := h.Hash([]byte("password"))
+	i.NoErr(err)
+	needsRehash, err = h.NeedsRehash(newHash)
+	i.NoErr(err)
+	i.True(!needsRehash)
+
+	parsed, err := ParseArgon2id(newHash)
+	i.NoErr(err)
+	i.Equal(string(parsed.KeyID), "k2")
+}
+
+func TestKeyringCopiesKeys(t *testing.T) {
+	i := is.New(t)
+	key := []byte("secret-1")
+	k, err := NewKeyring(Pepper{ID: "k1", Key: key})
+	i.NoErr(err)
+	key[0] = 'X'
+	i.Equal(string(k.Current().Key), "secret-1")
+
+	k.Current().Key[0] = 'X'
+	pepper, ok := k.Lookup("k1")
+	i.True(ok)
+	pepper.Key[1] = 'X'
+	i.Equal(string(k.Current().Key), "secret-1")
+}
+
+func TestPepperedArgon2idHasherUnknownKeyID(t *testing.T) {
+	i := is.New(t)
+	k1, err := NewKeyring(Pepper{ID: "k1", Key: []byte("secret-1")})
+	i.NoErr(err)
+	hashed, err := NewPepperedArgon2idHasher(k1, testArgon2idParams).Hash([]byte("password"))
+	i.NoErr(err)
+
+	k2, err := NewKeyring(Pepper{ID: "k2", Key: []byte("secret-2")})
+	i.NoErr(err)
+	_, err = NewPepperedArgon2idHasher(k2, testArgon2idParams).Verify(hashed, []byte("password"))
+	i.True(errors.Is(err, ErrUnknownKeyID))
+}
+
+func TestPepperedArgon2idHasherUnpepperedHash(t *testing.T) {
+	i := is.New(t)
+	k, err := NewKeyring(Pepper{ID: "k1", Key: []byte("secret-1")})
+	i.NoErr(err)
+	h := NewPepperedArgon2idHasher(k, testArgon2idParams)
+	legacy := Argon2idStringToStringWithParams("password", []byte("0123456789abcdef"), testArgon2idParams)
+
+	// unpeppered hashes are only accepted while migrating
+	match, err := h.Verify(legacy, []byte("password"))
+	i.True(errors.Is(err, ErrUnpeppered))
+	i.True(!match)
+
+	h.AllowUnpeppered = true
+	match, err = h.Verify(legacy, []byte("password"))
+	i.NoErr(err)
+	i.True(match)
+
+	needsRehash, err := h.NeedsRehash(legacy)
+	i.NoErr(err)
+	i.True(needsRehash)
+}
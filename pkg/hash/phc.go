@@ -0,0 +1,163 @@
+package hash
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMissingPrefix        = errors.New("invalid hash format: must start with $")
+	ErrInvalidFieldCount    = errors.New("invalid hash format")
+	ErrUnsupportedAlgorithm = errors.New("invalid algorithm")
+	ErrUnsupportedVersion   = errors.New("invalid version")
+	ErrMalformedParams      = errors.New("invalid parameters format")
+	ErrInvalidParams        = errors.New("invalid parameters")
+	ErrIllegalBase64        = errors.New("illegal base64")
+	ErrEmptySalt            = errors.New("invalid salt: cannot be empty")
+	ErrEmptyHash            = errors.New("invalid hash: cannot be empty")
+)
+
+// PHCParam is a single key=value parameter of a PHC string
+type PHCParam struct {
+	Key   string
+	Value string
+}
+
+// PHC is a parsed PHC string of the form $id[$v=version][$params][$salt[$hash]].
+// Version is zero if the string carries no version field; Salt and Hash are nil
+// if the corresponding field is absent.
+type PHC struct {
+	ID      string
+	Version int
+	Params  []PHCParam
+	Salt    []byte
+	Hash    []byte
+}
+
+// ParsePHC parses a PHC string without interpreting its parameters
+func ParsePHC(s string) (PHC, error) {
+	if !strings.HasPrefix(s, "$") {
+		return PHC{}, ErrMissingPrefix
+	}
+
+	fields := strings.Split(s[1:], "$")
+	phc := PHC{ID: fields[0]}
+	if phc.ID == "" {
+		return PHC{}, fmt.Errorf("%w: empty algorithm identifier", ErrUnsupportedAlgorithm)
+	}
+	fields = fields[1:]
+
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "v=") {
+		version, err := strconv.Atoi(fields[0][2:])
+		if err != nil || !isDecimal(fields[0][2:]) {
+			return PHC{}, fmt.Errorf("%w: %s", ErrUnsupportedVersion, fields[0])
+		}
+		phc.Version = version
+		fields = fields[1:]
+	}
+
+	if len(fields) > 0 && strings.Contains(fields[0], "=") {
+		params, err := parsePHCParams(fields[0])
+		if err != nil {
+			return PHC{}, err
+		}
+		phc.Params = params
+		fields = fields[1:]
+	}
+
+	if len(fields) > 2 {
+		return PHC{}, fmt.Errorf("%w: too many fields", ErrInvalidFieldCount)
+	}
+
+	if len(fields) > 0 {
+		salt, err := base64.RawStdEncoding.DecodeString(fields[0])
+		if err != nil {
+			return PHC{}, fmt.Errorf("%w in salt: %v", ErrIllegalBase64, err)
+		}
+		phc.Salt = salt
+	}
+
+	if len(fields) > 1 {
+		hash, err := base64.RawStdEncoding.DecodeString(fields[1])
+		if err != nil {
+			return PHC{}, fmt.Errorf("%w in hash: %v", ErrIllegalBase64, err)
+		}
+		phc.Hash = hash
+	}
+
+	return phc, nil
+}
+
+// Param returns the value of the parameter with the given key
+func (p PHC) Param(key string) (string, bool) {
+	for _, param := range p.Params {
+		if param.Key == key {
+			return param.Value, true
+		}
+	}
+	return "", false
+}
+
+// String formats p back into its PHC string representation
+func (p PHC) String() string {
+	var builder strings.Builder
+	builder.WriteByte('$')
+	builder.WriteString(p.ID)
+
+	if p.Version != 0 {
+		builder.WriteString("$v=")
+		builder.WriteString(strconv.Itoa(p.Version))
+	}
+
+	if len(p.Params) > 0 {
+		builder.WriteByte('$')
+		for i, param := range p.Params {
+			if i > 0 {
+				builder.WriteByte(',')
+			}
+			builder.WriteString(param.Key)
+			builder.WriteByte('=')
+			builder.WriteString(param.Value)
+		}
+	}
+
+	if p.Salt != nil || p.Hash != nil {
+		builder.WriteByte('$')
+		builder.WriteString(base64.RawStdEncoding.EncodeToString(p.Salt))
+	}
+
+	if p.Hash != nil {
+		builder.WriteByte('$')
+		builder.WriteString(base64.RawStdEncoding.EncodeToString(p.Hash))
+	}
+
+	return builder.String()
+}
+
+func parsePHCParams(field string) ([]PHCParam, error) {
+	pairs := strings.Split(field, ",")
+	params := make([]PHCParam, 0, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: %q is not a key=value pair", ErrMalformedParams, pair)
+		}
+		params = append(params, PHCParam{Key: key, Value: value})
+	}
+	return params, nil
+}
+
+func isDecimal(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
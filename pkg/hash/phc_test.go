@@ -0,0 +1,81 @@
+package hash
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestParsePHC(t *testing.T) {
+	i := is.New(t)
+	phc, err := ParsePHC("$argon2id$v=19$m=65536,t=2,p=1,keyid=a2V5$c2FsdHNhbHQ$aGFzaGhhc2g")
+	i.NoErr(err)
+	i.Equal(phc.ID, "argon2id")
+	i.Equal(phc.Version, 19)
+	i.Equal(len(phc.Params), 4)
+	i.Equal(string(phc.Salt), "saltsalt")
+	i.Equal(string(phc.Hash), "hashhash")
+
+	keyID, ok := phc.Param("keyid")
+	i.True(ok)
+	i.Equal(keyID, "a2V5")
+
+	_, ok = phc.Param("data")
+	i.True(!ok)
+}
+
+func TestParsePHCOptionalFields(t *testing.T) {
+	i := is.New(t)
+	phc, err := ParsePHC("$scrypt$ln=15,r=8,p=1")
+	i.NoErr(err)
+	i.Equal(phc.ID, "scrypt")
+	i.Equal(phc.Version, 0)
+	i.Equal(len(phc.Params), 3)
+	i.True(phc.Salt == nil)
+	i.True(phc.Hash == nil)
+
+	phc, err = ParsePHC("$md5$c2FsdA")
+	i.NoErr(err)
+	i.Equal(len(phc.Params), 0)
+	i.Equal(string(phc.Salt), "salt")
+}
+
+func TestPHCStringRoundTrip(t *testing.T) {
+	i := is.New(t)
+	for _, s := range []string{
+		"$argon2id$v=19$m=65536,t=2,p=1$c2FsdHNhbHQ$aGFzaGhhc2g",
+		"$scrypt$ln=15,r=8,p=1$c2FsdA$aGFzaA",
+		"$custom",
+	} {
+		phc, err := ParsePHC(s)
+		i.NoErr(err)
+		i.Equal(phc.String(), s)
+	}
+}
+
+func TestParsePHCErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		hash     string
+		expected error
+	}{
+		{name: "no prefix", hash: "argon2id", expected: ErrMissingPrefix},
+		{name: "empty id", hash: "$", expected: ErrUnsupportedAlgorithm},
+		{name: "non-decimal version", hash: "$argon2id$v=abc$m=1$c2FsdA$aGFzaA", expected: ErrUnsupportedVersion},
+		{name: "signed version", hash: "$argon2id$v=+19$m=1$c2FsdA$aGFzaA", expected: ErrUnsupportedVersion},
+		{name: "empty version", hash: "$argon2id$v=$m=1$c2FsdA$aGFzaA", expected: ErrUnsupportedVersion},
+		{name: "malformed param", hash: "$argon2id$v=19$m=1,t$c2FsdA$aGFzaA", expected: ErrMalformedParams},
+		{name: "too many fields", hash: "$argon2id$v=19$m=1$c2FsdA$aGFzaA$extra", expected: ErrInvalidFieldCount},
+		{name: "bad salt", hash: "$argon2id$v=19$m=1$>>>$aGFzaA", expected: ErrIllegalBase64},
+		{name: "bad hash", hash: "$argon2id$v=19$m=1$c2FsdA$>>>", expected: ErrIllegalBase64},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			is := is.New(t)
+			_, err := ParsePHC(tc.hash)
+			is.True(errors.Is(err, tc.expected))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package hash
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+type ScryptParams struct {
+	LogN   uint8
+	R      int
+	P      int
+	KeyLen int
+}
+
+var ScryptDefaultParams = ScryptParams{
+	LogN:   15,
+	R:      8,
+	P:      1,
+	KeyLen: 32,
+}
+
+const ScryptSaltLen = 16
+
+const (
+	// maxScryptMemory bounds the 128*r*2^ln bytes a stored hash may make Verify
+	// allocate, so a tampered hash cannot exhaust memory
+	maxScryptMemory = 1 << 30
+	maxScryptLogN   = 30
+	maxScryptR      = 1 << 10
+	maxScryptP      = 16
+)
+
+func generateScryptSalt() ([]byte, error) {
+	salt := make([]byte, ScryptSaltLen)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// ScryptHasher hashes passwords with scrypt into $scrypt$ln=..,r=..,p=..$salt$hash strings
+type ScryptHasher struct {
+	Params ScryptParams
+}
+
+func NewScryptHasher(p ScryptParams) *ScryptHasher {
+	return &ScryptHasher{Params: p}
+}
+
+func (h *ScryptHasher) Prefixes() []string {
+	return []string{"scrypt"}
+}
+
+func (h *ScryptHasher) Hash(password []byte) (string, error) {
+	salt, err := generateScryptSalt()
+	if err != nil {
+		return "", err
+	}
+	p := h.Params
+	hash, err := scrypt.Key(password, salt, 1<<p.LogN, p.R, p.P, p.KeyLen)
+	if err != nil {
+		return "", err
+	}
+	return PHC{
+		ID:     "scrypt",
+		Params: formatScryptParams(p),
+		Salt:   salt,
+		Hash:   hash,
+	}.String(), nil
+}
+
+func (h *ScryptHasher) Verify(hashedPassword string, password []byte) (bool, error) {
+	phc, err := ParsePHC(hashedPassword)
+	if err != nil {
+		return false, err
+	}
+	if phc.ID != "scrypt" {
+		return false, fmt.Errorf("%w: expected scrypt, got %s", ErrUnsupportedAlgorithm, phc.ID)
+	}
+	p, err := parseScryptParams(phc.Params)
+	if err != nil {
+		return false, err
+	}
+	if len(phc.Salt) == 0 {
+		return false, ErrEmptySalt
+	}
+	if len(phc.Hash) == 0 {
+		return false, ErrEmptyHash
+	}
+
+	computed, err := scrypt.Key(password, phc.Salt, 1<<p.LogN, p.R, p.P, len(phc.Hash))
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInvalidParams, err)
+	}
+	return equalHashes(phc.Hash, computed), nil
+}
+
+func (h *ScryptHasher) NeedsRehash(hashedPassword string) (bool, error) {
+	phc, err := ParsePHC(hashedPassword)
+	if err != nil {
+		return false, err
+	}
+	p, err := parseScryptParams(phc.Params)
+	if err != nil {
+		return false, err
+	}
+	return p.LogN < h.Params.LogN || p.R < h.Params.R || p.P < h.Params.P || len(phc.Hash) < h.Params.KeyLen, nil
+}
+
+func formatScryptParams(p ScryptParams) []PHCParam {
+	return []PHCParam{
+		{Key: "ln", Value: strconv.Itoa(int(p.LogN))},
+		{Key: "r", Value: strconv.Itoa(p.R)},
+		{Key: "p", Value: strconv.Itoa(p.P)},
+	}
+}
+
+func parseScryptParams(params []PHCParam) (ScryptParams, error) {
+	if len(params) != 3 || params[0].Key != "ln" || params[1].Key != "r" || params[2].Key != "p" {
+		return ScryptParams{}, fmt.Errorf("%w: failed to parse ln,r,p values", ErrMalformedParams)
+	}
+
+	logN, errN := strconv.ParseUint(params[0].Value, 10, 8)
+	r, errR := strconv.Atoi(params[1].Value)
+	p, errP := strconv.Atoi(params[2].Value)
+	if errN != nil || errR != nil || errP != nil {
+		return ScryptParams{}, fmt.Errorf("%w: failed to parse ln,r,p values", ErrMalformedParams)
+	}
+
+	if logN == 0 || logN > maxScryptLogN || r <= 0 || r > maxScryptR || p <= 0 || p > maxScryptP {
+		return ScryptParams{}, fmt.Errorf("%w: values out of range", ErrInvalidParams)
+	}
+	if 128*uint64(r)<<logN > maxScryptMemory {
+		return ScryptParams{}, fmt.Errorf("%w: 128*r*2^ln must not exceed %d bytes", ErrInvalidParams, maxScryptMemory)
+	}
+
+	return ScryptParams{LogN: uint8(logN), R: r, P: p}, nil
+}
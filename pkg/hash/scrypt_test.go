@@ -0,0 +1,47 @@
+package hash
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestScryptHasher(t *testing.T) {
+	i := is.New(t)
+	h := NewScryptHasher(ScryptParams{LogN: 4, R: 8, P: 1, KeyLen: 32})
+
+	hashed, err := h.Hash([]byte("password"))
+	i.NoErr(err)
+	i.True(strings.HasPrefix(hashed, "$scrypt$ln=4,r=8,p=1$"))
+
+	match, err := h.Verify(hashed, []byte("password"))
+	i.NoErr(err)
+	i.True(match)
+
+	match, err = h.Verify(hashed, []byte("wrongpassword"))
+	i.NoErr(err)
+	i.True(!match)
+
+	needsRehash, err := NewScryptHasher(ScryptParams{LogN: 5, R: 8, P: 1, KeyLen: 32}).NeedsRehash(hashed)
+	i.NoErr(err)
+	i.True(needsRehash)
+}
+
+func TestScryptHasherInvalidParams(t *testing.T) {
+	i := is.New(t)
+	h := NewScryptHasher(ScryptDefaultParams)
+
+	_, err := h.Verify("$scrypt$r=8,p=1$c2FsdA$aGFzaA", []byte("password"))
+	i.True(errors.Is(err, ErrMalformedParams))
+
+	_, err = h.Verify("$scrypt$ln=0,r=8,p=1$c2FsdA$aGFzaA", []byte("password"))
+	i.True(errors.Is(err, ErrInvalidParams))
+
+	// tampered hashes must not make Verify allocate 128*r*2^ln bytes
+	for _, params := range []string{"ln=63,r=8,p=1", "ln=21,r=8,p=1", "ln=14,r=1000000,p=1", "ln=14,r=8,p=1000000"} {
+		_, err = h.Verify("$scrypt$"+params+"$c2FsdA$aGFzaA", []byte("password"))
+		i.True(errors.Is(err, ErrInvalidParams))
+	}
+}
@@ -0,0 +1,39 @@
+package hash
+
+import (
+	"crypto/subtle"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// constantTimeCompare and argon2IDKey are variables so tests can assert that
+// every verification path goes through them
+var (
+	constantTimeCompare = subtle.ConstantTimeCompare
+	argon2IDKey         = argon2.IDKey
+)
+
+var dummySalt = make([]byte, Argon2idSaltLen)
+
+func equalHashes(a, b []byte) bool {
+	return constantTimeCompare(a, b) == 1
+}
+
+// DummyVerifyArgon2id burns the same Argon2id cost as verifying a password
+// against a hash created with Argon2idDefaultParams and always returns false.
+// Call it for unknown accounts so they take as long to reject as known ones.
+func DummyVerifyArgon2id(password []byte) bool {
+	return DummyVerifyArgon2idWithParams(password, Argon2idDefaultParams)
+}
+
+// DummyVerifyArgon2idWithParams is like DummyVerifyArgon2id for hashes created with p
+func DummyVerifyArgon2idWithParams(password []byte, p Argon2idParams) bool {
+	computed := Argon2idBytesWithParams(password, dummySalt, p)
+	equalHashes(computed, computed)
+	return false
+}
+
+// DummyVerifyArgon2idString is like DummyVerifyArgon2id for string passwords
+func DummyVerifyArgon2idString(password string) bool {
+	return DummyVerifyArgon2id([]byte(password))
+}
@@ -0,0 +1,103 @@
+package hash
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func countConstantTimeCompares(t *testing.T) *int {
+	calls := 0
+	original := constantTimeCompare
+	constantTimeCompare = func(a, b []byte) int {
+		calls++
+		return original(a, b)
+	}
+	t.Cleanup(func() { constantTimeCompare = original })
+	return &calls
+}
+
+func recordArgon2IDKeyMemory(t *testing.T) *[]uint32 {
+	var memory []uint32
+	original := argon2IDKey
+	argon2IDKey = func(password, salt []byte, time, mem uint32, threads uint8, keyLen uint32) []byte {
+		memory = append(memory, mem)
+		return original(password, salt, time, mem, threads, keyLen)
+	}
+	t.Cleanup(func() { argon2IDKey = original })
+	return &memory
+}
+
+func TestVerifyUsesConstantTimeCompare(t *testing.T) {
+	hashers := []Hasher{
+		NewArgon2idHasher(testArgon2idParams),
+		NewArgon2iHasher(testArgon2idParams),
+		NewScryptHasher(ScryptParams{LogN: 4, R: 8, P: 1, KeyLen: 32}),
+		NewPBKDF2Hasher(PBKDF2Params{Iterations: 1000, KeyLen: 32}),
+	}
+
+	for _, h := range hashers {
+		t.Run(h.Prefixes()[0], func(t *testing.T) {
+			is := is.New(t)
+			hashed, err := h.Hash([]byte("password"))
+			is.NoErr(err)
+
+			calls := countConstantTimeCompares(t)
+			_, err = h.Verify(hashed, []byte("wrongpassword"))
+			is.NoErr(err)
+			is.Equal(*calls, 1)
+		})
+	}
+}
+
+func TestVerifyArgon2idUsesConstantTimeCompare(t *testing.T) {
+	i := is.New(t)
+	hashed := Argon2idStringToStringWithParams("password", []byte("0123456789abcdef"), testArgon2idParams)
+
+	calls := countConstantTimeCompares(t)
+	match, err := VerifyArgon2idString(hashed, "password")
+	i.NoErr(err)
+	i.True(match)
+	i.Equal(*calls, 1)
+}
+
+func TestVerifyArgon2idMalformedHashBurnsCost(t *testing.T) {
+	i := is.New(t)
+	memory := recordArgon2IDKeyMemory(t)
+
+	_, err := VerifyArgon2id("$argon2id$v=19$m=0,t=1,p=1$c2FsdA$aGFzaA", []byte("password"))
+	i.True(err != nil)
+	i.Equal(*memory, []uint32{Argon2idDefaultParams.Memory})
+}
+
+func TestArgon2idHasherMalformedHashBurnsCost(t *testing.T) {
+	i := is.New(t)
+	memory := recordArgon2IDKeyMemory(t)
+
+	_, err := NewArgon2idHasher(testArgon2idParams).Verify("$argon2id$garbage", []byte("password"))
+	i.True(err != nil)
+	i.Equal(*memory, []uint32{testArgon2idParams.Memory})
+}
+
+func TestDummyVerifyArgon2id(t *testing.T) {
+	i := is.New(t)
+	memory := recordArgon2IDKeyMemory(t)
+	calls := countConstantTimeCompares(t)
+
+	i.True(!DummyVerifyArgon2idString("password"))
+	i.Equal(*memory, []uint32{Argon2idDefaultParams.Memory})
+	i.Equal(*calls, 1)
+}
+
+func TestRegistryDummyVerify(t *testing.T) {
+	i := is.New(t)
+	r := newTestRegistry()
+	memory := recordArgon2IDKeyMemory(t)
+
+	i.True(!r.DummyVerify([]byte("password")))
+	i.Equal(*memory, []uint32{testArgon2idParams.Memory})
+
+	_, err := r.Verify("$unknown$c2FsdA$aGFzaA", []byte("password"))
+	i.True(err != nil)
+	i.Equal(len(*memory), 2)
+}
@@ -0,0 +1,162 @@
+package hash
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"dario.lol/gotils/pkg/encoding"
+)
+
+var (
+	ErrMalformedToken      = errors.New("malformed token")
+	ErrInvalidToken        = errors.New("invalid token signature")
+	ErrExpiredToken        = errors.New("token expired")
+	ErrInvalidSigningKey   = errors.New("invalid signing key: id and key must not be empty")
+	ErrDuplicateSigningKey = errors.New("duplicate signing key id")
+	ErrUnknownSigningKeyID = errors.New("unknown signing key id")
+)
+
+// SigningKey is a token signing secret identified by ID. Keep signing keys
+// apart from peppers: both are used with HMAC-SHA256, so sharing a secret would
+// let one protocol's outputs stand in for the other's.
+type SigningKey struct {
+	ID  string
+	Key []byte
+}
+
+// SigningKeyring holds the key used for new tokens and all keys still accepted for verification
+type SigningKeyring struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewSigningKeyring creates a keyring signing with current and also verifying tokens signed with previous
+func NewSigningKeyring(current SigningKey, previous ...SigningKey) (*SigningKeyring, error) {
+	k := &SigningKeyring{
+		current: current.ID,
+		keys:    make(map[string][]byte, len(previous)+1),
+	}
+	for _, key := range append([]SigningKey{current}, previous...) {
+		if key.ID == "" || len(key.Key) == 0 {
+			return nil, ErrInvalidSigningKey
+		}
+		if _, ok := k.keys[key.ID]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateSigningKey, key.ID)
+		}
+		k.keys[key.ID] = bytes.Clone(key.Key)
+	}
+	return k, nil
+}
+
+// Current returns a copy of the key used for new tokens
+func (k *SigningKeyring) Current() SigningKey {
+	return SigningKey{ID: k.current, Key: bytes.Clone(k.keys[k.current])}
+}
+
+// Lookup returns a copy of the key with the given id
+func (k *SigningKeyring) Lookup(id string) (SigningKey, bool) {
+	key, ok := k.keys[id]
+	if !ok {
+		return SigningKey{}, false
+	}
+	return SigningKey{ID: id, Key: bytes.Clone(key)}, true
+}
+
+// Signer creates and verifies compact signed tokens of the form
+// keyid.payload.expiry.signature, where key id, payload and signature are
+// unpadded base64url and expiry is a unix timestamp in seconds (empty if the
+// token never expires). Tokens are signed with the keyring's current key and
+// verified with whichever key their key id names, so keys can be rotated.
+type Signer struct {
+	alg     MACAlgorithm
+	keyring *SigningKeyring
+	now     func() time.Time
+}
+
+// NewSigner creates a token signer using alg and the keys in k
+func NewSigner(alg MACAlgorithm, k *SigningKeyring) (*Signer, error) {
+	if _, err := NewMAC(alg, k.Current().Key); err != nil {
+		return nil, err
+	}
+	return &Signer{alg: alg, keyring: k, now: time.Now}, nil
+}
+
+// Sign creates a token for payload valid for ttl. A ttl of zero or less creates a token that never expires.
+func (s *Signer) Sign(payload []byte, ttl time.Duration) (string, error) {
+	key := s.keyring.Current()
+
+	var expiry string
+	if ttl > 0 {
+		expiry = strconv.FormatInt(s.now().Add(ttl).Unix(), 10)
+	}
+
+	unsigned := encoding.B64URLRawEncode(key.ID) + "." + encoding.B64URLRawEncodeBytes(payload) + "." + expiry
+	mac, err := MACString(s.alg, key.Key, unsigned)
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + encoding.B64URLRawEncodeBytes(mac), nil
+}
+
+// SignString is like Sign for string payloads
+func (s *Signer) SignString(payload string, ttl time.Duration) (string, error) {
+	return s.Sign([]byte(payload), ttl)
+}
+
+// Verify checks the token's signature and expiry and returns its payload
+func (s *Signer) Verify(token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("%w: expected 4 parts, got %d", ErrMalformedToken, len(parts))
+	}
+
+	keyID, err := encoding.B64URLRawDecode(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: key id: %v", ErrMalformedToken, err)
+	}
+	payload, err := encoding.B64URLRawDecodeToBytes(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: payload: %v", ErrMalformedToken, err)
+	}
+	var expiry int64
+	if parts[2] != "" {
+		expiry, err = strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: expiry: %v", ErrMalformedToken, err)
+		}
+	}
+	mac, err := encoding.B64URLRawDecodeToBytes(parts[3])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrMalformedToken, err)
+	}
+
+	key, ok := s.keyring.Lookup(keyID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSigningKeyID, keyID)
+	}
+	valid, err := VerifyMACString(s.alg, key.Key, token[:strings.LastIndexByte(token, '.')], mac)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, ErrInvalidToken
+	}
+
+	if parts[2] != "" && !s.now().Before(time.Unix(expiry, 0)) {
+		return nil, ErrExpiredToken
+	}
+	return payload, nil
+}
+
+// VerifyString is like Verify for string payloads
+func (s *Signer) VerifyString(token string) (string, error) {
+	payload, err := s.Verify(token)
+	if err != nil {
+		return "", err
+	}
+	return string(payload), nil
+}
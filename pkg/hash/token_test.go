@@ -0,0 +1,115 @@
+package hash
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func newTestSigner(t *testing.T, now time.Time, keys ...SigningKey) *Signer {
+	i := is.New(t)
+	k, err := NewSigningKeyring(keys[0], keys[1:]...)
+	i.NoErr(err)
+	s, err := NewSigner(HMACSHA256, k)
+	i.NoErr(err)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestSignerRoundTrip(t *testing.T) {
+	i := is.New(t)
+	now := time.Unix(1700000000, 0)
+	s := newTestSigner(t, now, SigningKey{ID: "k1", Key: []byte("secret-1")})
+
+	token, err := s.SignString("user=42", time.Minute)
+	i.NoErr(err)
+	i.Equal(strings.Count(token, "."), 3)
+	i.True(strings.HasPrefix(token, "azE.dXNlcj00Mg.1700000060."))
+
+	payload, err := s.VerifyString(token)
+	i.NoErr(err)
+	i.Equal(payload, "user=42")
+}
+
+func TestSignerExpiry(t *testing.T) {
+	i := is.New(t)
+	now := time.Unix(1700000000, 0)
+	s := newTestSigner(t, now, SigningKey{ID: "k1", Key: []byte("secret-1")})
+
+	token, err := s.SignString("csrf", time.Minute)
+	i.NoErr(err)
+
+	s.now = func() time.Time { return now.Add(time.Minute) }
+	_, err = s.Verify(token)
+	i.True(errors.Is(err, ErrExpiredToken))
+
+	forever, err := s.SignString("csrf", 0)
+	i.NoErr(err)
+	s.now = func() time.Time { return now.Add(100 * 365 * 24 * time.Hour) }
+	payload, err := s.VerifyString(forever)
+	i.NoErr(err)
+	i.Equal(payload, "csrf")
+}
+
+func TestSignerTampering(t *testing.T) {
+	i := is.New(t)
+	now := time.Unix(1700000000, 0)
+	s := newTestSigner(t, now, SigningKey{ID: "k1", Key: []byte("secret-1")})
+
+	token, err := s.SignString("user=42", time.Minute)
+	i.NoErr(err)
+	parts := strings.Split(token, ".")
+
+	extended := strings.Join([]string{parts[0], parts[1], "1800000000", parts[3]}, ".")
+	_, err = s.Verify(extended)
+	i.True(errors.Is(err, ErrInvalidToken))
+
+	_, err = s.Verify(parts[0] + "." + parts[1])
+	i.True(errors.Is(err, ErrMalformedToken))
+
+	_, err = s.Verify(strings.Join([]string{parts[0], "!!", parts[2], parts[3]}, "."))
+	i.True(errors.Is(err, ErrMalformedToken))
+}
+
+func TestSignerKeyRotation(t *testing.T) {
+	i := is.New(t)
+	now := time.Unix(1700000000, 0)
+	old := newTestSigner(t, now, SigningKey{ID: "k1", Key: []byte("secret-1")})
+	token, err := old.SignString("user=42", time.Minute)
+	i.NoErr(err)
+
+	rotated := newTestSigner(t, now, SigningKey{ID: "k2", Key: []byte("secret-2")}, SigningKey{ID: "k1", Key: []byte("secret-1")})
+	payload, err := rotated.VerifyString(token)
+	i.NoErr(err)
+	i.Equal(payload, "user=42")
+
+	fresh, err := rotated.SignString("user=42", time.Minute)
+	i.NoErr(err)
+	i.True(strings.HasPrefix(fresh, "azI."))
+
+	retired := newTestSigner(t, now, SigningKey{ID: "k2", Key: []byte("secret-2")})
+	_, err = retired.Verify(token)
+	i.True(errors.Is(err, ErrUnknownSigningKeyID))
+}
+
+func TestSigningKeyring(t *testing.T) {
+	i := is.New(t)
+	key := []byte("secret-1")
+	k, err := NewSigningKeyring(SigningKey{ID: "k1", Key: key})
+	i.NoErr(err)
+	key[0] = 'X'
+	i.Equal(string(k.Current().Key), "secret-1")
+	k.Current().Key[0] = 'X'
+	signingKey, ok := k.Lookup("k1")
+	i.True(ok)
+	signingKey.Key[1] = 'X'
+	i.Equal(string(k.Current().Key), "secret-1")
+
+	_, err = NewSigningKeyring(SigningKey{ID: "k1"})
+	i.True(errors.Is(err, ErrInvalidSigningKey))
+	_, err = NewSigningKeyring(SigningKey{ID: "k1", Key: key}, SigningKey{ID: "k1", Key: key})
+	i.True(errors.Is(err, ErrDuplicateSigningKey))
+}
@@ -0,0 +1,134 @@
+package hash
+
+import (
+	"encoding/binary"
+	stdhash "hash"
+	"math/bits"
+)
+
+const (
+	xxhPrime1 uint64 = 11400714785074694791
+	xxhPrime2 uint64 = 14029467366897019727
+	xxhPrime3 uint64 = 1609587929392839161
+	xxhPrime4 uint64 = 9650029242287828579
+	xxhPrime5 uint64 = 2870177450012600261
+)
+
+// xxh64 is a streaming implementation of the XXH64 non-cryptographic hash
+type xxh64 struct {
+	seed  uint64
+	v     [4]uint64
+	total uint64
+	buf   [32]byte
+	n     int
+}
+
+// NewXXH64 returns a streaming XXH64 hash with the given seed
+func NewXXH64(seed uint64) stdhash.Hash64 {
+	x := &xxh64{seed: seed}
+	x.Reset()
+	return x
+}
+
+// XXH64 returns the XXH64 hash of data with seed 0
+func XXH64(data []byte) uint64 {
+	x := NewXXH64(0)
+	x.Write(data)
+	return x.Sum64()
+}
+
+func (x *xxh64) Reset() {
+	x.v = [4]uint64{
+		x.seed + xxhPrime1 + xxhPrime2,
+		x.seed + xxhPrime2,
+		x.seed,
+		x.seed - xxhPrime1,
+	}
+	x.total = 0
+	x.n = 0
+}
+
+func (x *xxh64) Size() int {
+	return 8
+}
+
+func (x *xxh64) BlockSize() int {
+	return 32
+}
+
+func (x *xxh64) Write(p []byte) (int, error) {
+	written := len(p)
+	x.total += uint64(written)
+
+	if x.n > 0 {
+		copied := copy(x.buf[x.n:], p)
+		x.n += copied
+		p = p[copied:]
+		if x.n < len(x.buf) {
+			return written, nil
+		}
+		x.consume(x.buf[:])
+		x.n = 0
+	}
+
+	for len(p) >= 32 {
+		x.consume(p[:32])
+		p = p[32:]
+	}
+
+	x.n = copy(x.buf[:], p)
+	return written, nil
+}
+
+func (x *xxh64) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, x.Sum64())
+}
+
+func (x *xxh64) Sum64() uint64 {
+	var h uint64
+	if x.total >= 32 {
+		h = bits.RotateLeft64(x.v[0], 1) + bits.RotateLeft64(x.v[1], 7) +
+			bits.RotateLeft64(x.v[2], 12) + bits.RotateLeft64(x.v[3], 18)
+		for _, v := range x.v {
+			h ^= xxhRound(0, v)
+			h = h*xxhPrime1 + xxhPrime4
+		}
+	} else {
+		h = x.seed + xxhPrime5
+	}
+	h += x.total
+
+	p := x.buf[:x.n]
+	for ; len(p) >= 8; p = p[8:] {
+		h ^= xxhRound(0, binary.LittleEndian.Uint64(p))
+		h = bits.RotateLeft64(h, 27)*xxhPrime1 + xxhPrime4
+	}
+	if len(p) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(p)) * xxhPrime1
+		h = bits.RotateLeft64(h, 23)*xxhPrime2 + xxhPrime3
+		p = p[4:]
+	}
+	for _, b := range p {
+		h ^= uint64(b) * xxhPrime5
+		h = bits.RotateLeft64(h, 11) * xxhPrime1
+	}
+
+	h ^= h >> 33
+	h *= xxhPrime2
+	h ^= h >> 29
+	h *= xxhPrime3
+	h ^= h >> 32
+	return h
+}
+
+func (x *xxh64) consume(block []byte) {
+	for i := range x.v {
+		x.v[i] = xxhRound(x.v[i], binary.LittleEndian.Uint64(block[i*8:]))
+	}
+}
+
+func xxhRound(acc, input uint64) uint64 {
+	acc += input * xxhPrime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * xxhPrime1
+}
@@ -0,0 +1,55 @@
+package hash
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestXXH64Vectors(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected uint64
+	}{
+		{input: "", expected: 0xef46db3751d8e999},
+		{input: "a", expected: 0xd24ec4f1a98c6e5b},
+		{input: "abc", expected: 0x44bc2cf5ad770999},
+		{input: "Nobody inspects the spammish repetition", expected: 0xfbcea83c8a378bf1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(XXH64([]byte(tc.input)), tc.expected)
+		})
+	}
+}
+
+func TestXXH64Streaming(t *testing.T) {
+	i := is.New(t)
+	data := bytes.Repeat([]byte("0123456789abcdefghijklmnopqrstuvwxyz"), 10)
+	expected := XXH64(data)
+
+	for _, chunk := range []int{1, 3, 7, 31, 32, 33, 100} {
+		h := NewXXH64(0)
+		for start := 0; start < len(data); start += chunk {
+			h.Write(data[start:min(start+chunk, len(data))])
+		}
+		i.Equal(h.Sum64(), expected)
+	}
+
+	h := NewXXH64(0)
+	h.Write(data)
+	h.Reset()
+	h.Write([]byte("abc"))
+	i.Equal(h.Sum64(), uint64(0x44bc2cf5ad770999))
+	i.Equal(h.Sum(nil), []byte{0x44, 0xbc, 0x2c, 0xf5, 0xad, 0x77, 0x09, 0x99})
+}
+
+func TestXXH64Seed(t *testing.T) {
+	i := is.New(t)
+	seeded := NewXXH64(1)
+	seeded.Write([]byte("abc"))
+	i.True(seeded.Sum64() != XXH64([]byte("abc")))
+}
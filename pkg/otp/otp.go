@@ -0,0 +1,304 @@
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"math"
+	"strings"
+	"time"
+)
+
+// Algorithm is the HMAC hash function, named as in otpauth URIs
+type Algorithm string
+
+const (
+	SHA1   Algorithm = "SHA1"
+	SHA256 Algorithm = "SHA256"
+	SHA512 Algorithm = "SHA512"
+)
+
+const (
+	// DefaultSecretSize is the secret length in bytes, the HMAC-SHA1 output size recommended by RFC 4226
+	DefaultSecretSize = 20
+	minDigits         = 6
+	maxDigits         = 10
+)
+
+var (
+	ErrInvalidSecret        = errors.New("invalid secret: must be base32 encoded")
+	ErrInvalidDigits        = errors.New("invalid digits: must be between 6 and 10")
+	ErrInvalidPeriod        = errors.New("invalid period: must be a positive number of whole seconds")
+	ErrInvalidSkew          = errors.New("invalid skew: must not be negative")
+	ErrInvalidCounter       = errors.New("invalid counter: look-ahead window exceeds the counter range")
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+	ErrReplayedCode         = errors.New("one-time password was already used")
+)
+
+// secretEncoding is base32 without padding, as used by authenticator apps
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Config holds one-time password settings
+type Config struct {
+	Digits    int
+	Algorithm Algorithm
+	// Period is the TOTP time step
+	Period time.Duration
+	// Skew is the number of steps accepted before and after the current TOTP
+	// step, or after the expected HOTP counter
+	Skew int
+	// Clock returns the current time for TOTP
+	Clock func() time.Time
+	// ReplayGuard rejects codes whose counter was accepted before, nil disables it
+	ReplayGuard ReplayGuard
+	// Issuer names the service in provisioning URIs
+	Issuer string
+}
+
+// Option is a function that modifies Config
+type Option func(*Config)
+
+func newConfig(options []Option) (Config, error) {
+	config := Config{
+		Digits:    6,
+		Algorithm: SHA1,
+		Period:    30 * time.Second,
+		Skew:      1,
+		Clock:     time.Now,
+	}
+	for _, opt := range options {
+		opt(&config)
+	}
+	if config.Digits < minDigits || config.Digits > maxDigits {
+		return config, ErrInvalidDigits
+	}
+	if config.Period < time.Second || config.Period%time.Second != 0 {
+		return config, ErrInvalidPeriod
+	}
+	if config.Skew < 0 {
+		return config, ErrInvalidSkew
+	}
+	if _, err := config.Algorithm.hash(); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+func (a Algorithm) hash() (func() hash.Hash, error) {
+	switch a {
+	case SHA1:
+		return sha1.New, nil
+	case SHA256:
+		return sha256.New, nil
+	case SHA512:
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, a)
+}
+
+// GenerateSecret returns a random base32 secret of size bytes (default: DefaultSecretSize) from crypto/rand
+func GenerateSecret(size ...int) (string, error) {
+	n := DefaultSecretSize
+	if len(size) > 0 && size[0] > 0 {
+		n = size[0]
+	}
+	secret := make([]byte, n)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return secretEncoding.EncodeToString(secret), nil
+}
+
+// DecodeSecret decodes a base32 secret, ignoring case, spaces and padding
+func DecodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	key, err := secretEncoding.DecodeString(strings.TrimRight(secret, "="))
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	return key, nil
+}
+
+// HOTP returns the RFC 4226 code of secret for counter
+func HOTP(secret string, counter uint64, options ...Option) (string, error) {
+	config, err := newConfig(options)
+	if err != nil {
+		return "", err
+	}
+	key, err := DecodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return generateCode(config, key, counter), nil
+}
+
+// VerifyHOTP checks code against the counters counter to counter+Skew. On
+// success it returns the counter to store for the next verification. Counters
+// whose window or next counter would wrap around fail with ErrInvalidCounter.
+func VerifyHOTP(secret, code string, counter uint64, options ...Option) (bool, uint64, error) {
+	config, err := newConfig(options)
+	if err != nil {
+		return false, counter, err
+	}
+	if counter >= math.MaxUint64-uint64(config.Skew) {
+		return false, counter, ErrInvalidCounter
+	}
+	key, err := DecodeSecret(secret)
+	if err != nil {
+		return false, counter, err
+	}
+	for c := counter; c <= counter+uint64(config.Skew); c++ {
+		if match(config, key, code, c) {
+			if err := config.use(c); err != nil {
+				return false, counter, err
+			}
+			return true, c + 1, nil
+		}
+	}
+	return false, counter, nil
+}
+
+// TOTP returns the RFC 6238 code of secret for the current time of Clock
+func TOTP(secret string, options ...Option) (string, error) {
+	config, err := newConfig(options)
+	if err != nil {
+		return "", err
+	}
+	return TOTPAt(secret, config.Clock(), options...)
+}
+
+// TOTPAt returns the RFC 6238 code of secret at t
+func TOTPAt(secret string, t time.Time, options ...Option) (string, error) {
+	config, err := newConfig(options)
+	if err != nil {
+		return "", err
+	}
+	key, err := DecodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return generateCode(config, key, config.step(t)), nil
+}
+
+// VerifyTOTP checks code against the current time step of Clock and Skew steps
+// before and after it, to tolerate clock drift and typing delays
+func VerifyTOTP(secret, code string, options ...Option) (bool, error) {
+	config, err := newConfig(options)
+	if err != nil {
+		return false, err
+	}
+	key, err := DecodeSecret(secret)
+	if err != nil {
+		return false, err
+	}
+	step := config.step(config.Clock())
+	for offset := -config.Skew; offset <= config.Skew; offset++ {
+		if offset < 0 && uint64(-offset) > step {
+			continue
+		}
+		c := step + uint64(offset)
+		if match(config, key, code, c) {
+			if err := config.use(c); err != nil {
+				return false, err
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// step returns the TOTP time step of t, times before the unix epoch map to step 0
+func (c Config) step(t time.Time) uint64 {
+	return uint64(max(t.Unix(), 0)) / uint64(c.Period/time.Second)
+}
+
+func (c Config) use(counter uint64) error {
+	if c.ReplayGuard == nil {
+		return nil
+	}
+	fresh, err := c.ReplayGuard.Use(counter)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrReplayedCode
+	}
+	return nil
+}
+
+// generateCode computes the dynamically truncated HMAC of counter, RFC 4226 section 5.3
+func generateCode(config Config, key []byte, counter uint64) string {
+	h, _ := config.Algorithm.hash()
+	mac := hmac.New(h, key)
+	_ = binary.Write(mac, binary.BigEndian, counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := uint64(binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff)
+	mod := uint64(1)
+	for range config.Digits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", config.Digits, value%mod)
+}
+
+func match(config Config, key []byte, candidate string, counter uint64) bool {
+	return subtle.ConstantTimeCompare([]byte(generateCode(config, key, counter)), []byte(candidate)) == 1
+}
+
+// WithDigitsOption sets the code length (default: 6)
+func WithDigitsOption(digits int) Option {
+	return func(c *Config) {
+		c.Digits = digits
+	}
+}
+
+// WithAlgorithmOption sets the HMAC hash function (default: SHA1)
+func WithAlgorithmOption(alg Algorithm) Option {
+	return func(c *Config) {
+		c.Algorithm = alg
+	}
+}
+
+// WithPeriodOption sets the TOTP time step (default: 30 seconds)
+func WithPeriodOption(period time.Duration) Option {
+	return func(c *Config) {
+		c.Period = period
+	}
+}
+
+// WithSkewOption sets the number of steps accepted around the expected one (default: 1)
+func WithSkewOption(steps int) Option {
+	return func(c *Config) {
+		c.Skew = steps
+	}
+}
+
+// WithClockOption sets the time source for TOTP (default: time.Now)
+func WithClockOption(clock func() time.Time) Option {
+	return func(c *Config) {
+		c.Clock = clock
+	}
+}
+
+// WithReplayGuardOption rejects codes whose counter guard reports as used
+func WithReplayGuardOption(guard ReplayGuard) Option {
+	return func(c *Config) {
+		c.ReplayGuard = guard
+	}
+}
+
+// WithIssuerOption sets the service name shown by authenticator apps
+func WithIssuerOption(issuer string) Option {
+	return func(c *Config) {
+		c.Issuer = issuer
+	}
+}
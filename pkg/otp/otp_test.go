@@ -0,0 +1,217 @@
+package otp
+
+import (
+	"encoding/base32"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+var (
+	// RFC 4226 and RFC 6238 test secrets
+	secretSHA1   = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	secretSHA256 = base32.StdEncoding.EncodeToString([]byte("12345678901234567890123456789012"))
+	secretSHA512 = base32.StdEncoding.EncodeToString([]byte("1234567890123456789012345678901234567890123456789012345678901234"))
+)
+
+func fixedClock(unix int64) Option {
+	return WithClockOption(func() time.Time { return time.Unix(unix, 0) })
+}
+
+func TestHOTPRFC4226(t *testing.T) {
+	i := is.New(t)
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		got, err := HOTP(secretSHA1, uint64(counter))
+		i.NoErr(err)
+		i.Equal(got, code)
+	}
+}
+
+func TestTOTPRFC6238(t *testing.T) {
+	tests := []struct {
+		unix   int64
+		sha1   string
+		sha256 string
+		sha512 string
+	}{
+		{59, "94287082", "46119246", "90693936"},
+		{1111111109, "07081804", "68084774", "25091201"},
+		{1111111111, "14050471", "67062674", "99943326"},
+		{1234567890, "89005924", "91819424", "93441116"},
+		{2000000000, "69279037", "90698825", "38618901"},
+		{20000000000, "65353130", "77737706", "47863826"},
+	}
+	for _, tt := range tests {
+		t.Run(time.Unix(tt.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			is := is.New(t)
+			for _, alg := range []struct {
+				alg    Algorithm
+				secret string
+				want   string
+			}{
+				{SHA1, secretSHA1, tt.sha1},
+				{SHA256, secretSHA256, tt.sha256},
+				{SHA512, secretSHA512, tt.sha512},
+			} {
+				got, err := TOTP(alg.secret, WithAlgorithmOption(alg.alg), WithDigitsOption(8), fixedClock(tt.unix))
+				is.NoErr(err)
+				is.Equal(got, alg.want)
+			}
+		})
+	}
+}
+
+func TestVerifyTOTPSkew(t *testing.T) {
+	i := is.New(t)
+	code, err := TOTPAt(secretSHA1, time.Unix(1_000_000_020, 0))
+	i.NoErr(err)
+
+	tests := []struct {
+		name    string
+		unix    int64
+		options []Option
+		want    bool
+	}{
+		{"same step", 1_000_000_020, nil, true},
+		{"next step", 1_000_000_050, nil, true},
+		{"previous step", 1_000_000_000 - 10, nil, true},
+		{"two steps later", 1_000_000_080, nil, false},
+		{"two steps later with skew 2", 1_000_000_080, []Option{WithSkewOption(2)}, true},
+		{"next step without skew", 1_000_000_050, []Option{WithSkewOption(0)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			ok, err := VerifyTOTP(secretSHA1, code, append(tt.options, fixedClock(tt.unix))...)
+			is.NoErr(err)
+			is.Equal(ok, tt.want)
+		})
+	}
+}
+
+func TestVerifyTOTPRejectsWrongCode(t *testing.T) {
+	i := is.New(t)
+	ok, err := VerifyTOTP(secretSHA1, "000000", fixedClock(59))
+	i.NoErr(err)
+	i.True(!ok)
+	ok, err = VerifyTOTP(secretSHA1, "", fixedClock(59))
+	i.NoErr(err)
+	i.True(!ok)
+}
+
+func TestVerifyHOTP(t *testing.T) {
+	i := is.New(t)
+	code, err := HOTP(secretSHA1, 5)
+	i.NoErr(err)
+
+	ok, next, err := VerifyHOTP(secretSHA1, code, 5)
+	i.NoErr(err)
+	i.True(ok)
+	i.Equal(next, uint64(6))
+
+	// look-ahead of one counter by default
+	ok, next, err = VerifyHOTP(secretSHA1, code, 4)
+	i.NoErr(err)
+	i.True(ok)
+	i.Equal(next, uint64(6))
+
+	ok, next, err = VerifyHOTP(secretSHA1, code, 3)
+	i.NoErr(err)
+	i.True(!ok)
+	i.Equal(next, uint64(3))
+
+	ok, _, err = VerifyHOTP(secretSHA1, code, 6)
+	i.NoErr(err)
+	i.True(!ok)
+}
+
+func TestVerifyHOTPCounterOverflow(t *testing.T) {
+	i := is.New(t)
+	for _, skew := range []int{0, 1, 5} {
+		ok, next, err := VerifyHOTP(secretSHA1, "000000", math.MaxUint64-uint64(skew), WithSkewOption(skew))
+		i.True(errors.Is(err, ErrInvalidCounter))
+		i.True(!ok)
+		i.Equal(next, math.MaxUint64-uint64(skew))
+	}
+
+	// the last usable counter still verifies and its successor does not wrap
+	code, err := HOTP(secretSHA1, math.MaxUint64-2)
+	i.NoErr(err)
+	ok, next, err := VerifyHOTP(secretSHA1, code, math.MaxUint64-2)
+	i.NoErr(err)
+	i.True(ok)
+	i.Equal(next, uint64(math.MaxUint64-1))
+}
+
+func TestInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		option Option
+		err    error
+	}{
+		{"too few digits", WithDigitsOption(5), ErrInvalidDigits},
+		{"too many digits", WithDigitsOption(11), ErrInvalidDigits},
+		{"zero period", WithPeriodOption(0), ErrInvalidPeriod},
+		{"fractional period", WithPeriodOption(1500 * time.Millisecond), ErrInvalidPeriod},
+		{"negative skew", WithSkewOption(-1), ErrInvalidSkew},
+		{"unknown algorithm", WithAlgorithmOption("MD5"), ErrUnsupportedAlgorithm},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			_, err := TOTP(secretSHA1, tt.option)
+			is.True(errors.Is(err, tt.err))
+		})
+	}
+}
+
+func TestDigitsAndPeriod(t *testing.T) {
+	i := is.New(t)
+	code, err := TOTP(secretSHA1, WithDigitsOption(10), WithPeriodOption(60*time.Second), fixedClock(59))
+	i.NoErr(err)
+	i.Equal(len(code), 10)
+
+	// 59 and 0 share a 60 second step
+	same, err := TOTP(secretSHA1, WithDigitsOption(10), WithPeriodOption(60*time.Second), fixedClock(0))
+	i.NoErr(err)
+	i.Equal(code, same)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	i := is.New(t)
+	secret, err := GenerateSecret()
+	i.NoErr(err)
+	key, err := DecodeSecret(secret)
+	i.NoErr(err)
+	i.Equal(len(key), DefaultSecretSize)
+
+	other, err := GenerateSecret()
+	i.NoErr(err)
+	i.True(secret != other)
+
+	secret, err = GenerateSecret(32)
+	i.NoErr(err)
+	key, err = DecodeSecret(secret)
+	i.NoErr(err)
+	i.Equal(len(key), 32)
+}
+
+func TestDecodeSecret(t *testing.T) {
+	i := is.New(t)
+	key, err := DecodeSecret("gezd gnbv gy3t qojq gezd gnbv gy3t qojq")
+	i.NoErr(err)
+	i.Equal(string(key), "12345678901234567890")
+
+	key, err = DecodeSecret("MFRGG===")
+	i.NoErr(err)
+	i.Equal(string(key), "abc")
+
+	for _, secret := range []string{"", "not base32!", "===="} {
+		_, err := DecodeSecret(secret)
+		i.True(errors.Is(err, ErrInvalidSecret))
+	}
+}
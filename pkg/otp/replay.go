@@ -0,0 +1,53 @@
+package otp
+
+import "sync"
+
+// ReplayGuard records the counters of accepted codes, so a code cannot be used
+// twice. TOTP counters are time steps.
+type ReplayGuard interface {
+	// Use records counter and reports whether it was not used before
+	Use(counter uint64) (bool, error)
+}
+
+// ReplayGuardFunc adapts a function to the ReplayGuard interface, for example
+// to store the last accepted counter in a database
+type ReplayGuardFunc func(counter uint64) (bool, error)
+
+func (f ReplayGuardFunc) Use(counter uint64) (bool, error) {
+	return f(counter)
+}
+
+// CounterGuard accepts only counters greater than the last accepted one, as
+// RFC 6238 section 5.2 requires. It is safe for concurrent use; keep one per user.
+type CounterGuard struct {
+	mu       sync.Mutex
+	last     uint64
+	accepted bool
+}
+
+// NewCounterGuard creates a guard for a user without accepted codes
+func NewCounterGuard() *CounterGuard {
+	return &CounterGuard{}
+}
+
+// NewCounterGuardFrom creates a guard that already accepted last, for example loaded from storage
+func NewCounterGuardFrom(last uint64) *CounterGuard {
+	return &CounterGuard{last: last, accepted: true}
+}
+
+func (g *CounterGuard) Use(counter uint64) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.accepted && counter <= g.last {
+		return false, nil
+	}
+	g.last, g.accepted = counter, true
+	return true, nil
+}
+
+// Last returns the last accepted counter and whether any was accepted
+func (g *CounterGuard) Last() (uint64, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.last, g.accepted
+}
@@ -0,0 +1,56 @@
+package otp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestVerifyTOTPReplay(t *testing.T) {
+	i := is.New(t)
+	guard := NewCounterGuard()
+	clock := fixedClock(1_000_000_020)
+	code, err := TOTP(secretSHA1, clock)
+	i.NoErr(err)
+
+	ok, err := VerifyTOTP(secretSHA1, code, clock, WithReplayGuardOption(guard))
+	i.NoErr(err)
+	i.True(ok)
+
+	ok, err = VerifyTOTP(secretSHA1, code, clock, WithReplayGuardOption(guard))
+	i.True(errors.Is(err, ErrReplayedCode))
+	i.True(!ok)
+
+	// a code of an earlier step within the skew window is rejected as well
+	previous, err := TOTPAt(secretSHA1, time.Unix(1_000_000_020-30, 0))
+	i.NoErr(err)
+	_, err = VerifyTOTP(secretSHA1, previous, clock, WithReplayGuardOption(guard))
+	i.True(errors.Is(err, ErrReplayedCode))
+
+	last, ok := guard.Last()
+	i.True(ok)
+	i.Equal(last, uint64(1_000_000_020/30))
+}
+
+func TestReplayGuardFunc(t *testing.T) {
+	i := is.New(t)
+	storeErr := errors.New("store unavailable")
+	guard := ReplayGuardFunc(func(counter uint64) (bool, error) { return false, storeErr })
+	code, err := HOTP(secretSHA1, 3)
+	i.NoErr(err)
+	_, _, err = VerifyHOTP(secretSHA1, code, 3, WithReplayGuardOption(guard))
+	i.True(errors.Is(err, storeErr))
+}
+
+func TestCounterGuardFrom(t *testing.T) {
+	i := is.New(t)
+	guard := NewCounterGuardFrom(10)
+	fresh, err := guard.Use(10)
+	i.NoErr(err)
+	i.True(!fresh)
+	fresh, err = guard.Use(11)
+	i.NoErr(err)
+	i.True(fresh)
+}
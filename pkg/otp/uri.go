@@ -0,0 +1,61 @@
+package otp
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidAccount = errors.New("invalid account: must not be empty")
+
+// TOTPURI builds the otpauth:// URI authenticator apps import, usually shown
+// as a QR code, for a TOTP secret of account
+func TOTPURI(secret, account string, options ...Option) (string, error) {
+	return provisioningURI("totp", secret, account, options, func(c Config, q url.Values) {
+		q.Set("period", strconv.FormatInt(int64(c.Period/time.Second), 10))
+	})
+}
+
+// HOTPURI builds the otpauth:// URI for an HOTP secret of account starting at counter
+func HOTPURI(secret, account string, counter uint64, options ...Option) (string, error) {
+	return provisioningURI("hotp", secret, account, options, func(_ Config, q url.Values) {
+		q.Set("counter", strconv.FormatUint(counter, 10))
+	})
+}
+
+// provisioningURI follows the Key Uri Format of Google Authenticator:
+// otpauth://type/issuer:account?secret=..&issuer=..&algorithm=..&digits=..
+func provisioningURI(kind, secret, account string, options []Option, extra func(Config, url.Values)) (string, error) {
+	config, err := newConfig(options)
+	if err != nil {
+		return "", err
+	}
+	key, err := DecodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	if account == "" {
+		return "", ErrInvalidAccount
+	}
+
+	label := account
+	q := url.Values{}
+	// the canonical form, whatever spacing, case or padding the caller used
+	q.Set("secret", secretEncoding.EncodeToString(key))
+	if config.Issuer != "" {
+		label = config.Issuer + ":" + account
+		q.Set("issuer", config.Issuer)
+	}
+	q.Set("algorithm", string(config.Algorithm))
+	q.Set("digits", strconv.Itoa(config.Digits))
+	extra(config, q)
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     kind,
+		Path:     "/" + label,
+		RawQuery: q.Encode(),
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,69 @@
+package otp
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestTOTPURI(t *testing.T) {
+	i := is.New(t)
+	uri, err := TOTPURI("JBSWY3DPEHPK3PXP", "alice@example.com", WithIssuerOption("Acme Co"))
+	i.NoErr(err)
+	i.Equal(uri, "otpauth://totp/Acme%20Co:alice@example.com?algorithm=SHA1&digits=6&issuer=Acme+Co&period=30&secret=JBSWY3DPEHPK3PXP")
+
+	u, err := url.Parse(uri)
+	i.NoErr(err)
+	i.Equal(u.Path, "/Acme Co:alice@example.com")
+}
+
+func TestURINormalizesSecret(t *testing.T) {
+	i := is.New(t)
+	uri, err := TOTPURI("jbsw y3dp ehpk 3pxp", "alice")
+	i.NoErr(err)
+	u, err := url.Parse(uri)
+	i.NoErr(err)
+	i.Equal(u.Query().Get("secret"), "JBSWY3DPEHPK3PXP")
+
+	uri, err = TOTPURI("MFRGG===", "alice")
+	i.NoErr(err)
+	u, err = url.Parse(uri)
+	i.NoErr(err)
+	i.Equal(u.Query().Get("secret"), "MFRGG")
+}
+
+func TestTOTPURIOptions(t *testing.T) {
+	i := is.New(t)
+	uri, err := TOTPURI("JBSWY3DPEHPK3PXP", "alice",
+		WithAlgorithmOption(SHA256), WithDigitsOption(8), WithPeriodOption(60*time.Second))
+	i.NoErr(err)
+	u, err := url.Parse(uri)
+	i.NoErr(err)
+	q := u.Query()
+	i.Equal(u.Host, "totp")
+	i.Equal(u.Path, "/alice")
+	i.Equal(q.Get("algorithm"), "SHA256")
+	i.Equal(q.Get("digits"), "8")
+	i.Equal(q.Get("period"), "60")
+	i.Equal(q.Get("issuer"), "")
+}
+
+func TestHOTPURI(t *testing.T) {
+	i := is.New(t)
+	uri, err := HOTPURI("JBSWY3DPEHPK3PXP", "alice", 42, WithIssuerOption("Acme"))
+	i.NoErr(err)
+	i.Equal(uri, "otpauth://hotp/Acme:alice?algorithm=SHA1&counter=42&digits=6&issuer=Acme&secret=JBSWY3DPEHPK3PXP")
+}
+
+func TestURIErrors(t *testing.T) {
+	i := is.New(t)
+	_, err := TOTPURI("not base32!", "alice")
+	i.True(errors.Is(err, ErrInvalidSecret))
+	_, err = TOTPURI("JBSWY3DPEHPK3PXP", "")
+	i.True(errors.Is(err, ErrInvalidAccount))
+	_, err = HOTPURI("JBSWY3DPEHPK3PXP", "alice", 0, WithDigitsOption(4))
+	i.True(errors.Is(err, ErrInvalidDigits))
+}
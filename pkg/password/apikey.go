@@ -0,0 +1,152 @@
+package password
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+
+	"dario.lol/gotils/pkg/hash"
+)
+
+const (
+	base62Chars = numberChars + upperChars + lowerChars
+	// apiKeyChecksumLength base62 digits hold every CRC-32 value
+	apiKeyChecksumLength = 6
+	defaultAPIKeyLength  = 32
+	minAPIKeyLength      = 16
+)
+
+var (
+	ErrInvalidAPIKeyPrefix = errors.New("invalid API key prefix: must only contain letters, digits and underscores")
+	ErrInvalidAPIKeyLength = errors.New("invalid API key length: at least 16 random characters required")
+	ErrMalformedAPIKey     = errors.New("malformed API key")
+	ErrAPIKeyChecksum      = errors.New("API key checksum mismatch")
+)
+
+// APIKeyConfig holds API key generation settings
+type APIKeyConfig struct {
+	// Length is the number of random base62 characters
+	Length int
+	// Rand is the source of randomness, crypto/rand if nil
+	Rand io.Reader
+}
+
+// APIKeyOption is a function that modifies APIKeyConfig
+type APIKeyOption func(*APIKeyConfig)
+
+// GenerateAPIKey creates a key of the form <prefix>_<random><checksum> such as
+// gt_live_3XvO...Qk9f2B. The random part is base62 and the checksum is the
+// CRC-32 of everything before it, so typos and truncated keys are detected by
+// ValidateAPIKey without a database lookup. The default 32 random characters
+// hold about 190 bits of entropy.
+func GenerateAPIKey(prefix string, options ...APIKeyOption) (string, error) {
+	config := APIKeyConfig{Length: defaultAPIKeyLength}
+	for _, opt := range options {
+		opt(&config)
+	}
+	if !validAPIKeyPrefix(prefix) {
+		return "", ErrInvalidAPIKeyPrefix
+	}
+	if config.Length < minAPIKeyLength {
+		return "", ErrInvalidAPIKeyLength
+	}
+
+	src := newRandomSource(config.Rand)
+	charset := []rune(base62Chars)
+	var key strings.Builder
+	key.WriteString(prefix)
+	key.WriteByte('_')
+	for range config.Length {
+		c, err := src.char(charset)
+		if err != nil {
+			return "", err
+		}
+		key.WriteRune(c)
+	}
+	checksum, err := apiKeyChecksum(key.String())
+	if err != nil {
+		return "", err
+	}
+	key.WriteString(checksum)
+	return key.String(), nil
+}
+
+// MustGenerateAPIKey is a helper that wraps GenerateAPIKey and panics if an error occurs
+func MustGenerateAPIKey(prefix string, options ...APIKeyOption) string {
+	key, err := GenerateAPIKey(prefix, options...)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// ValidateAPIKey checks the format and checksum of key and returns its prefix
+func ValidateAPIKey(key string) (string, error) {
+	i := strings.LastIndexByte(key, '_')
+	if i < 0 {
+		return "", ErrMalformedAPIKey
+	}
+	prefix, body := key[:i], key[i+1:]
+	if !validAPIKeyPrefix(prefix) || len(body) < minAPIKeyLength+apiKeyChecksumLength ||
+		strings.Trim(body, base62Chars) != "" {
+		return "", ErrMalformedAPIKey
+	}
+
+	split := len(key) - apiKeyChecksumLength
+	checksum, err := apiKeyChecksum(key[:split])
+	if err != nil {
+		return "", err
+	}
+	if checksum != key[split:] {
+		return "", ErrAPIKeyChecksum
+	}
+	return prefix, nil
+}
+
+// HashAPIKey returns the hex SHA-256 digest of key for storage. A fast hash is
+// enough because generated keys are too long to be guessed, unlike passwords.
+func HashAPIKey(key string) (string, error) {
+	return hash.DigestHex(hash.DigestSHA256, []byte(key))
+}
+
+// VerifyAPIKey reports whether key has the stored digest hashed, comparing in constant time
+func VerifyAPIKey(hashed, key string) (bool, error) {
+	if _, err := ValidateAPIKey(key); err != nil {
+		return false, err
+	}
+	return hash.VerifyDigest(hash.DigestSHA256, []byte(key), hashed)
+}
+
+func validAPIKeyPrefix(prefix string) bool {
+	return prefix != "" && strings.Trim(prefix, base62Chars+"_") == ""
+}
+
+// apiKeyChecksum encodes the CRC-32 of s as fixed-width base62
+func apiKeyChecksum(s string) (string, error) {
+	sum, err := hash.Digest(hash.DigestCRC32, []byte(s))
+	if err != nil {
+		return "", err
+	}
+	n := binary.BigEndian.Uint32(sum)
+	checksum := make([]byte, apiKeyChecksumLength)
+	for i := len(checksum) - 1; i >= 0; i-- {
+		checksum[i] = base62Chars[n%62]
+		n /= 62
+	}
+	return string(checksum), nil
+}
+
+// APIKeyWithLengthOption sets the number of random characters (default: 32, minimum: 16)
+func APIKeyWithLengthOption(length int) APIKeyOption {
+	return func(c *APIKeyConfig) {
+		c.Length = length
+	}
+}
+
+// APIKeyWithRandOption reads randomness from r instead of crypto/rand
+func APIKeyWithRandOption(r io.Reader) APIKeyOption {
+	return func(c *APIKeyConfig) {
+		c.Rand = r
+	}
+}
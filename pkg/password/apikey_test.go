@@ -0,0 +1,112 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	i := is.New(t)
+	key, err := GenerateAPIKey("gt_live")
+	i.NoErr(err)
+	i.True(strings.HasPrefix(key, "gt_live_"))
+	i.Equal(len(key), len("gt_live_")+defaultAPIKeyLength+apiKeyChecksumLength)
+	i.Equal(strings.Trim(strings.TrimPrefix(key, "gt_live_"), base62Chars), "")
+
+	prefix, err := ValidateAPIKey(key)
+	i.NoErr(err)
+	i.Equal(prefix, "gt_live")
+
+	other, err := GenerateAPIKey("gt_live")
+	i.NoErr(err)
+	i.True(key != other)
+}
+
+func TestGenerateAPIKeyOptions(t *testing.T) {
+	i := is.New(t)
+	key, err := GenerateAPIKey("gt", APIKeyWithLengthOption(40), APIKeyWithRandOption(seededReader(1)))
+	i.NoErr(err)
+	i.Equal(len(key), len("gt_")+40+apiKeyChecksumLength)
+
+	same, err := GenerateAPIKey("gt", APIKeyWithLengthOption(40), APIKeyWithRandOption(seededReader(1)))
+	i.NoErr(err)
+	i.Equal(key, same)
+
+	_, err = GenerateAPIKey("gt", APIKeyWithLengthOption(8))
+	i.True(errors.Is(err, ErrInvalidAPIKeyLength))
+	for _, prefix := range []string{"", "gt-live", "gt live"} {
+		_, err = GenerateAPIKey(prefix)
+		i.True(errors.Is(err, ErrInvalidAPIKeyPrefix))
+	}
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	key := MustGenerateAPIKey("gt_live")
+	body := key[len("gt_live_"):]
+	flipped := []byte(key)
+	if flipped[10] == 'a' {
+		flipped[10] = 'b'
+	} else {
+		flipped[10] = 'a'
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		err  error
+	}{
+		{"valid", key, nil},
+		{"typo", string(flipped), ErrAPIKeyChecksum},
+		{"other prefix", "gt_test_" + body, ErrAPIKeyChecksum},
+		{"truncated", key[:len(key)-1], ErrAPIKeyChecksum},
+		{"too short", "gt_live_abc", ErrMalformedAPIKey},
+		{"no prefix", body, ErrMalformedAPIKey},
+		{"invalid characters", "gt_live_" + strings.Repeat("!", 30), ErrMalformedAPIKey},
+		{"empty", "", ErrMalformedAPIKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			_, err := ValidateAPIKey(tt.key)
+			if tt.err == nil {
+				is.NoErr(err)
+				return
+			}
+			is.True(errors.Is(err, tt.err))
+		})
+	}
+}
+
+func TestHashAPIKey(t *testing.T) {
+	i := is.New(t)
+	key := MustGenerateAPIKey("gt_live")
+	hashed, err := HashAPIKey(key)
+	i.NoErr(err)
+	i.Equal(len(hashed), 64)
+	i.True(!strings.Contains(hashed, key))
+
+	ok, err := VerifyAPIKey(hashed, key)
+	i.NoErr(err)
+	i.True(ok)
+
+	ok, err = VerifyAPIKey(hashed, MustGenerateAPIKey("gt_live"))
+	i.NoErr(err)
+	i.True(!ok)
+
+	_, err = VerifyAPIKey(hashed, key[:len(key)-1])
+	i.True(errors.Is(err, ErrAPIKeyChecksum))
+}
+
+func TestAPIKeyChecksum(t *testing.T) {
+	i := is.New(t)
+	// CRC-32 of the empty string is 0, the largest CRC-32 fits six base62 digits
+	checksum, err := apiKeyChecksum("")
+	i.NoErr(err)
+	i.Equal(checksum, "000000")
+	checksum, err = apiKeyChecksum("gt_live_abc")
+	i.NoErr(err)
+	i.Equal(len(checksum), apiKeyChecksumLength)
+}
@@ -0,0 +1,336 @@
+package password
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	// maxBloomFilterBits bounds the size of a bloom filter to 8 GiB, enough
+	// for the full HIBP list at a false positive rate of one in a million
+	maxBloomFilterBits = 1 << 36
+	// maxBloomFilterHashes bounds the hash functions probed per lookup
+	maxBloomFilterHashes = 64
+)
+
+var (
+	ErrInvalidBloomFilter = errors.New("invalid bloom filter")
+	ErrMalformedHashList  = errors.New("malformed hash list")
+	ErrUnsortedHashList   = errors.New("hash list is not sorted")
+	ErrMalformedHashFile  = errors.New("malformed hash file: size must be a multiple of 20 bytes")
+)
+
+// Blocklist reports whether a password appears in a list of common or breached passwords
+type Blocklist interface {
+	Contains(password string) (bool, error)
+}
+
+// PasswordSet is an in-memory blocklist matching passwords case-insensitively
+type PasswordSet struct {
+	passwords map[string]struct{}
+}
+
+// NewPasswordSet creates a blocklist of the given passwords
+func NewPasswordSet(passwords ...string) *PasswordSet {
+	s := &PasswordSet{passwords: make(map[string]struct{}, len(passwords))}
+	for _, p := range passwords {
+		s.passwords[strings.ToLower(p)] = struct{}{}
+	}
+	return s
+}
+
+// CommonPasswords is the embedded list of the most common passwords
+var CommonPasswords = NewPasswordSet(strings.Fields(commonPasswordsData)...)
+
+func (s *PasswordSet) Contains(password string) (bool, error) {
+	_, ok := s.passwords[strings.ToLower(password)]
+	return ok, nil
+}
+
+// Len returns the number of passwords in the set
+func (s *PasswordSet) Len() int {
+	return len(s.passwords)
+}
+
+// IsBreached reports whether password is in any of lists, or in CommonPasswords if no lists are given
+func IsBreached(password string, lists ...Blocklist) (bool, error) {
+	if len(lists) == 0 {
+		return CommonPasswords.Contains(password)
+	}
+	for _, l := range lists {
+		found, err := l.Contains(password)
+		if err != nil || found {
+			return found, err
+		}
+	}
+	return false, nil
+}
+
+// BloomFilter is a compact probabilistic blocklist of SHA-1 password digests.
+// It never misses an added password but reports false positives at roughly the
+// rate it was created with. Lookups are case-sensitive, like HIBP.
+type BloomFilter struct {
+	bits   []uint64
+	m      uint64
+	hashes uint32
+}
+
+// NewBloomFilter creates a bloom filter sized for expected entries at the given
+// false positive rate. Filters needing more than 2^36 bits fail with
+// ErrInvalidBloomFilter, at most 64 hash functions are used.
+func NewBloomFilter(expected int, falsePositiveRate float64) (*BloomFilter, error) {
+	if expected <= 0 || !(falsePositiveRate > 0 && falsePositiveRate < 1) {
+		return nil, fmt.Errorf("%w: expected must be positive and false positive rate between 0 and 1", ErrInvalidBloomFilter)
+	}
+	bits := math.Ceil(-float64(expected) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	if bits > maxBloomFilterBits {
+		return nil, fmt.Errorf("%w: %.0f bits exceed the maximum of %d", ErrInvalidBloomFilter, bits, uint64(maxBloomFilterBits))
+	}
+	m := max(uint64(bits), 64)
+	k := uint32(min(max(math.Round(float64(m)/float64(expected)*math.Ln2), 1), maxBloomFilterHashes))
+	return &BloomFilter{
+		bits:   make([]uint64, (m+63)/64),
+		m:      m,
+		hashes: k,
+	}, nil
+}
+
+// Add adds password to the filter
+func (f *BloomFilter) Add(password string) {
+	f.AddSHA1(sha1.Sum([]byte(password)))
+}
+
+// AddSHA1 adds a password by its SHA-1 digest, as found in HIBP dumps
+func (f *BloomFilter) AddSHA1(digest [sha1.Size]byte) {
+	h1, h2 := bloomHashes(digest)
+	for i := range uint64(f.hashes) {
+		idx := (h1 + i*h2) % f.m
+		f.bits[idx/64] |= 1 << (idx % 64)
+	}
+}
+
+func (f *BloomFilter) Contains(password string) (bool, error) {
+	return f.ContainsSHA1(sha1.Sum([]byte(password))), nil
+}
+
+// ContainsSHA1 reports whether a password with the given SHA-1 digest may have been added
+func (f *BloomFilter) ContainsSHA1(digest [sha1.Size]byte) bool {
+	h1, h2 := bloomHashes(digest)
+	for i := range uint64(f.hashes) {
+		idx := (h1 + i*h2) % f.m
+		if f.bits[idx/64]&(1<<(idx%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalBinary encodes the filter so it can be stored and loaded without rebuilding it
+func (f *BloomFilter) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 12, 12+8*len(f.bits))
+	binary.BigEndian.PutUint32(buf[0:4], f.hashes)
+	binary.BigEndian.PutUint64(buf[4:12], f.m)
+	for _, word := range f.bits {
+		buf = binary.BigEndian.AppendUint64(buf, word)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a filter created by MarshalBinary
+func (f *BloomFilter) UnmarshalBinary(data []byte) error {
+	if len(data) < 12 {
+		return fmt.Errorf("%w: truncated header", ErrInvalidBloomFilter)
+	}
+	hashes := binary.BigEndian.Uint32(data[0:4])
+	m := binary.BigEndian.Uint64(data[4:12])
+	payload := uint64(len(data) - 12)
+	// compare against the payload without computing (m+63)/64, which overflows for huge m
+	if hashes == 0 || m == 0 || m > payload*8 || payload != ((m-1)/64+1)*8 {
+		return fmt.Errorf("%w: size does not match header", ErrInvalidBloomFilter)
+	}
+	if hashes > maxBloomFilterHashes || m > maxBloomFilterBits {
+		return fmt.Errorf("%w: at most %d hashes and %d bits are supported", ErrInvalidBloomFilter, maxBloomFilterHashes, uint64(maxBloomFilterBits))
+	}
+	words := payload / 8
+	f.hashes, f.m = hashes, m
+	f.bits = make([]uint64, words)
+	for i := range f.bits {
+		f.bits[i] = binary.BigEndian.Uint64(data[12+8*i:])
+	}
+	return nil
+}
+
+func bloomHashes(digest [sha1.Size]byte) (uint64, uint64) {
+	return binary.BigEndian.Uint64(digest[0:8]), binary.BigEndian.Uint64(digest[8:16]) | 1
+}
+
+// ScanHIBP calls fn with every SHA-1 digest of a HIBP style hash list, one
+// HEX[:COUNT] entry per line. For range files, which only hold hash suffixes,
+// prefix is the 5 character range they were downloaded for, otherwise it is empty.
+func ScanHIBP(r io.Reader, prefix string, fn func(digest [sha1.Size]byte) error) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		entry, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if entry == "" {
+			continue
+		}
+		var digest [sha1.Size]byte
+		full := prefix + entry
+		if len(full) != hex.EncodedLen(sha1.Size) {
+			return fmt.Errorf("%w: line %d is not a SHA-1 hash", ErrMalformedHashList, line)
+		}
+		if _, err := hex.Decode(digest[:], []byte(full)); err != nil {
+			return fmt.Errorf("%w: line %d is not a SHA-1 hash", ErrMalformedHashList, line)
+		}
+		if err := fn(digest); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// LoadBloomFilter reads the HIBP style hash list at path into a new bloom filter
+// sized for expected entries
+func LoadBloomFilter(path, prefix string, expected int, falsePositiveRate float64) (*BloomFilter, error) {
+	f, err := NewBloomFilter(expected, falsePositiveRate)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	err = ScanHIBP(file, prefix, func(digest [sha1.Size]byte) error {
+		f.AddSHA1(digest)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// HashFile is a blocklist backed by a file of sorted binary SHA-1 digests. It is
+// searched with binary search on disk, so even very large lists use no memory.
+// Lookups are case-sensitive, like HIBP.
+type HashFile struct {
+	r    io.ReaderAt
+	n    int64
+	file *os.File
+}
+
+// NewHashFile creates a blocklist reading size bytes of sorted digests from r
+func NewHashFile(r io.ReaderAt, size int64) (*HashFile, error) {
+	if size%sha1.Size != 0 {
+		return nil, ErrMalformedHashFile
+	}
+	return &HashFile{r: r, n: size / sha1.Size}, nil
+}
+
+// OpenHashFile opens a hash file created by WriteHashFile
+func OpenHashFile(path string) (*HashFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	h, err := NewHashFile(file, info.Size())
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	h.file = file
+	return h, nil
+}
+
+// Len returns the number of digests in the file
+func (h *HashFile) Len() int64 {
+	return h.n
+}
+
+func (h *HashFile) Contains(password string) (bool, error) {
+	return h.ContainsSHA1(sha1.Sum([]byte(password)))
+}
+
+// ContainsSHA1 reports whether the file holds digest
+func (h *HashFile) ContainsSHA1(digest [sha1.Size]byte) (bool, error) {
+	var (
+		buf     [sha1.Size]byte
+		readErr error
+	)
+	idx := sort.Search(int(h.n), func(i int) bool {
+		if readErr != nil {
+			return true
+		}
+		if _, err := h.r.ReadAt(buf[:], int64(i)*sha1.Size); err != nil {
+			readErr = err
+			return true
+		}
+		return bytes.Compare(buf[:], digest[:]) >= 0
+	})
+	if readErr != nil {
+		return false, readErr
+	}
+	if idx >= int(h.n) {
+		return false, nil
+	}
+	if _, err := h.r.ReadAt(buf[:], int64(idx)*sha1.Size); err != nil {
+		return false, err
+	}
+	return buf == digest, nil
+}
+
+// Close closes the underlying file if the hash file was opened with OpenHashFile
+func (h *HashFile) Close() error {
+	if h.file == nil {
+		return nil
+	}
+	return h.file.Close()
+}
+
+// WriteHashFile converts a HIBP style hash list into the binary format read by
+// HashFile. The list must be sorted by hash, as HIBP downloads are; duplicates
+// are written once. It returns the number of digests written.
+func WriteHashFile(w io.Writer, r io.Reader, prefix string) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var (
+		last    [sha1.Size]byte
+		written int64
+	)
+	err := ScanHIBP(r, prefix, func(digest [sha1.Size]byte) error {
+		if written > 0 {
+			switch c := bytes.Compare(digest[:], last[:]); {
+			case c == 0:
+				return nil
+			case c < 0:
+				return fmt.Errorf("%w: %X after %X", ErrUnsortedHashList, digest, last)
+			}
+		}
+		if _, err := bw.Write(digest[:]); err != nil {
+			return err
+		}
+		last = digest
+		written++
+		return nil
+	})
+	if err != nil {
+		return written, err
+	}
+	return written, bw.Flush()
+}
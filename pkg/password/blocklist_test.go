@@ -0,0 +1,253 @@
+package password
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func hibpList(passwords ...string) string {
+	var hashes []string
+	for _, p := range passwords {
+		sum := sha1.Sum([]byte(p))
+		hashes = append(hashes, strings.ToUpper(hex.EncodeToString(sum[:])))
+	}
+	slices.Sort(hashes)
+	var b strings.Builder
+	for n, h := range hashes {
+		fmt.Fprintf(&b, "%s:%d\r\n", h, n+1)
+	}
+	return b.String()
+}
+
+func TestCommonPasswords(t *testing.T) {
+	i := is.New(t)
+	i.True(CommonPasswords.Len() > 400)
+
+	for _, p := range []string{"password", "PASSWORD", "qwerty123", "Password1"} {
+		found, err := IsBreached(p)
+		i.NoErr(err)
+		i.True(found)
+	}
+	found, err := IsBreached("Xk9#mQ2$vL7!pR4")
+	i.NoErr(err)
+	i.True(!found)
+}
+
+func TestIsBreachedWithLists(t *testing.T) {
+	i := is.New(t)
+	custom := NewPasswordSet("hunter2")
+	found, err := IsBreached("Hunter2", custom)
+	i.NoErr(err)
+	i.True(found)
+
+	// explicit lists replace the embedded list
+	found, err = IsBreached("password", custom)
+	i.NoErr(err)
+	i.True(!found)
+}
+
+func TestBloomFilter(t *testing.T) {
+	i := is.New(t)
+	f, err := NewBloomFilter(1000, 0.01)
+	i.NoErr(err)
+	for n := range 1000 {
+		f.Add(fmt.Sprintf("breached-%d", n))
+	}
+	for n := range 1000 {
+		found, err := f.Contains(fmt.Sprintf("breached-%d", n))
+		i.NoErr(err)
+		i.True(found)
+	}
+
+	var falsePositives int
+	for n := range 10000 {
+		if found, _ := f.Contains(fmt.Sprintf("unknown-%d", n)); found {
+			falsePositives++
+		}
+	}
+	i.True(falsePositives < 300) // 1% expected, leave room for variance
+}
+
+func TestBloomFilterMarshal(t *testing.T) {
+	i := is.New(t)
+	f, err := NewBloomFilter(100, 0.001)
+	i.NoErr(err)
+	f.Add("hunter2")
+
+	data, err := f.MarshalBinary()
+	i.NoErr(err)
+	var loaded BloomFilter
+	i.NoErr(loaded.UnmarshalBinary(data))
+	found, err := loaded.Contains("hunter2")
+	i.NoErr(err)
+	i.True(found)
+
+	err = loaded.UnmarshalBinary(data[:len(data)-1])
+	i.True(errors.Is(err, ErrInvalidBloomFilter))
+}
+
+func TestBloomFilterUnmarshalCraftedHeader(t *testing.T) {
+	header := func(hashes uint32, m uint64, payload int) []byte {
+		data := binary.BigEndian.AppendUint32(nil, hashes)
+		data = binary.BigEndian.AppendUint64(data, m)
+		return append(data, make([]byte, payload)...)
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"overflowing m without bits", header(3, math.MaxUint64, 0)},
+		{"overflowing m with bits", header(3, math.MaxUint64, 8)},
+		{"m beyond payload", header(3, 65, 8)},
+		{"payload beyond m", header(3, 64, 16)},
+		{"zero m", header(3, 0, 0)},
+		{"zero hashes", header(0, 64, 8)},
+		{"too many hashes", header(maxBloomFilterHashes+1, 64, 8)},
+		{"billions of hashes", header(math.MaxUint32, 64, 8)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			var f BloomFilter
+			is.True(errors.Is(f.UnmarshalBinary(tt.data), ErrInvalidBloomFilter))
+		})
+	}
+
+	i := is.New(t)
+	var f BloomFilter
+	i.NoErr(f.UnmarshalBinary(header(3, 65, 16)))
+	found, err := f.Contains("hunter2")
+	i.NoErr(err)
+	i.True(!found)
+}
+
+func TestNewBloomFilterInvalid(t *testing.T) {
+	i := is.New(t)
+	_, err := NewBloomFilter(0, 0.01)
+	i.True(errors.Is(err, ErrInvalidBloomFilter))
+	_, err = NewBloomFilter(10, 1)
+	i.True(errors.Is(err, ErrInvalidBloomFilter))
+	_, err = NewBloomFilter(10, math.NaN())
+	i.True(errors.Is(err, ErrInvalidBloomFilter))
+	_, err = NewBloomFilter(math.MaxInt, 1e-9)
+	i.True(errors.Is(err, ErrInvalidBloomFilter))
+}
+
+func TestNewBloomFilterCapsHashes(t *testing.T) {
+	i := is.New(t)
+	// a rate of 1e-30 would call for about 100 hash functions
+	f, err := NewBloomFilter(10, 1e-30)
+	i.NoErr(err)
+	i.Equal(f.hashes, uint32(maxBloomFilterHashes))
+	f.Add("hunter2")
+	found, err := f.Contains("hunter2")
+	i.NoErr(err)
+	i.True(found)
+
+	data, err := f.MarshalBinary()
+	i.NoErr(err)
+	var loaded BloomFilter
+	i.NoErr(loaded.UnmarshalBinary(data))
+}
+
+func TestScanHIBP(t *testing.T) {
+	i := is.New(t)
+	var digests [][sha1.Size]byte
+	err := ScanHIBP(strings.NewReader(hibpList("a", "b")+"\n"), "", func(d [sha1.Size]byte) error {
+		digests = append(digests, d)
+		return nil
+	})
+	i.NoErr(err)
+	i.Equal(len(digests), 2)
+
+	// range files hold suffixes of a 5 character prefix
+	sum := sha1.Sum([]byte("password"))
+	full := strings.ToUpper(hex.EncodeToString(sum[:]))
+	err = ScanHIBP(strings.NewReader(full[5:]+":3861493\n"), full[:5], func(d [sha1.Size]byte) error {
+		i.Equal(d, sum)
+		return nil
+	})
+	i.NoErr(err)
+
+	err = ScanHIBP(strings.NewReader("nothex:1\n"), "", func([sha1.Size]byte) error { return nil })
+	i.True(errors.Is(err, ErrMalformedHashList))
+}
+
+func TestLoadBloomFilter(t *testing.T) {
+	i := is.New(t)
+	path := filepath.Join(t.TempDir(), "hibp.txt")
+	i.NoErr(os.WriteFile(path, []byte(hibpList("hunter2", "letmein")), 0o644))
+
+	f, err := LoadBloomFilter(path, "", 2, 0.001)
+	i.NoErr(err)
+	found, err := IsBreached("hunter2", f)
+	i.NoErr(err)
+	i.True(found)
+}
+
+func TestHashFile(t *testing.T) {
+	i := is.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hibp.bin")
+
+	var buf bytes.Buffer
+	passwords := []string{"hunter2", "letmein", "correct horse", "trustno1", "hunter2"}
+	n, err := WriteHashFile(&buf, strings.NewReader(hibpList(passwords...)), "")
+	i.NoErr(err)
+	i.Equal(n, int64(4))
+	i.NoErr(os.WriteFile(path, buf.Bytes(), 0o644))
+
+	h, err := OpenHashFile(path)
+	i.NoErr(err)
+	defer h.Close()
+	i.Equal(h.Len(), int64(4))
+
+	for _, p := range passwords {
+		found, err := h.Contains(p)
+		i.NoErr(err)
+		i.True(found)
+	}
+	for _, p := range []string{"Hunter2", "", "zzzz", "0"} {
+		found, err := h.Contains(p)
+		i.NoErr(err)
+		i.True(!found)
+	}
+}
+
+func TestWriteHashFileUnsorted(t *testing.T) {
+	i := is.New(t)
+	list := hibpList("a", "b")
+	lines := strings.SplitAfter(list, "\n")
+	_, err := WriteHashFile(&bytes.Buffer{}, strings.NewReader(lines[1]+lines[0]), "")
+	i.True(errors.Is(err, ErrUnsortedHashList))
+}
+
+func TestNewHashFileMalformed(t *testing.T) {
+	i := is.New(t)
+	_, err := NewHashFile(bytes.NewReader(make([]byte, 21)), 21)
+	i.True(errors.Is(err, ErrMalformedHashFile))
+}
+
+func TestVerifyWithBlocklist(t *testing.T) {
+	i := is.New(t)
+	err := Verify("Password1!", VerifyWithBlocklistOption())
+	i.NoErr(err)
+
+	err = Verify("P@ssw0rd", VerifyWithBlocklistOption())
+	i.True(errors.Is(err, ErrBreachedPassword))
+
+	err = Verify("Hunter2!x", VerifyWithBlocklistOption(NewPasswordSet("hunter2!x")))
+	i.True(errors.Is(err, ErrBreachedPassword))
+}
@@ -1,10 +1,12 @@
 package password
 
 import (
-	"crypto/rand"
 	"errors"
-	"math/big"
+	"fmt"
+	"io"
+	"slices"
 	"strings"
+	"unicode"
 )
 
 const (
@@ -12,10 +14,30 @@ const (
 	lowerChars   = "abcdefghijklmnopqrstuvwxyz"
 	numberChars  = "0123456789"
 	specialChars = "!@#$%^&*()_+-=[]{}|;:,.<>?"
+	// lookAlikeChars are easily confused when read or typed
+	lookAlikeChars = "0O1lI"
+
+	// maxBatchAttemptsFactor bounds GenerateBatch to this many attempts per requested password
+	maxBatchAttemptsFactor = 100
 )
 
 var (
 	ErrInvalidCharset = errors.New("invalid charset: no characters available for password generation")
+	ErrInvalidLength  = errors.New("invalid length: minimum class counts exceed password length")
+	ErrBatchExhausted = errors.New("not enough unique passwords: generation space too small for batch")
+	ErrInvalidCount   = errors.New("invalid count: must not be negative")
+)
+
+// GenerateMode selects how Generate builds passwords
+type GenerateMode int
+
+const (
+	// ModeRandom draws characters uniformly from the charset
+	ModeRandom GenerateMode = iota
+	// ModePronounceable joins random syllables, see GenerateWithPronounceableOption
+	ModePronounceable
+	// ModePattern fills placeholders of a template, see GenerateWithPatternOption
+	ModePattern
 )
 
 // GenerateConfig holds password generation settings
@@ -27,70 +49,275 @@ type GenerateConfig struct {
 	UseSpecial    bool
 	CustomCharset string
 	ExcludeChars  string
+	// MinUpper, MinLower, MinNumbers and MinSpecial are guaranteed counts of each
+	// enabled class that has characters available, also within CustomCharset.
+	// The default of one per class is dropped when Length cannot hold them all.
+	MinUpper   int
+	MinLower   int
+	MinNumbers int
+	MinSpecial int
+	// MaxRepeats limits runs of identical characters in ModeRandom, zero disables the limit
+	MaxRepeats int
+	Mode       GenerateMode
+	Pattern    string
+	// ExcludeLookAlikes excludes the easily confused characters 0O1lI in every mode
+	ExcludeLookAlikes bool
+	// Rand is the source of randomness, crypto/rand if nil
+	Rand io.Reader
+
+	// explicitMins marks the minimums set by options, see minUpperSet
+	explicitMins uint8
 }
 
+const (
+	minUpperSet uint8 = 1 << iota
+	minLowerSet
+	minNumbersSet
+	minSpecialSet
+)
+
 // GenerateOption is a function that modifies GenerateConfig
 type GenerateOption func(*GenerateConfig)
 
-// Generate creates a random password based on the specified options
+type charClass struct {
+	chars []rune
+	min   int
+}
+
+// Generate creates a random password based on the specified options. By
+// default it contains at least one character of every enabled class.
 func Generate(options ...GenerateOption) (string, error) {
+	config := newGenerateConfig(options)
+	return generate(config, newRandomSource(config.Rand))
+}
+
+func newGenerateConfig(options []GenerateOption) GenerateConfig {
 	config := GenerateConfig{
 		Length:     16,
 		UseUpper:   true,
 		UseLower:   true,
 		UseNumbers: true,
 		UseSpecial: true,
+		MinUpper:   1,
+		MinLower:   1,
+		MinNumbers: 1,
+		MinSpecial: 1,
 	}
 
 	for _, opt := range options {
 		opt(&config)
 	}
+	if config.ExcludeLookAlikes {
+		config.ExcludeChars += lookAlikeChars
+	}
+	config.fitDefaultMins()
+	return config
+}
+
+// fitDefaultMins drops the implicit one-per-class minimums if Length cannot hold
+// them together with the explicit ones, so short passwords keep working.
+// Explicit minimums are left alone and still fail with ErrInvalidLength.
+func (c *GenerateConfig) fitDefaultMins() {
+	var required int
+	for _, class := range c.classes() {
+		required += class.min
+	}
+	if required <= c.Length {
+		return
+	}
+	for _, m := range []struct {
+		set uint8
+		min *int
+	}{
+		{minUpperSet, &c.MinUpper},
+		{minLowerSet, &c.MinLower},
+		{minNumbersSet, &c.MinNumbers},
+		{minSpecialSet, &c.MinSpecial},
+	} {
+		if c.explicitMins&m.set == 0 {
+			*m.min = 0
+		}
+	}
+}
 
-	var charset strings.Builder
+func generate(config GenerateConfig, src *randomSource) (string, error) {
+	switch config.Mode {
+	case ModePattern:
+		return generatePattern(config, src)
+	case ModePronounceable:
+		return generatePronounceable(config, src)
+	default:
+		return generateRandom(config, src)
+	}
+}
 
-	if config.CustomCharset != "" {
-		charset.WriteString(config.CustomCharset)
-	} else {
-		if config.UseUpper {
-			charset.WriteString(upperChars)
+func generateRandom(config GenerateConfig, src *randomSource) (string, error) {
+	classes := config.classes()
+	var required int
+	for _, class := range classes {
+		required += class.min
+	}
+
+	charset := config.charset(classes)
+	if len(charset) == 0 {
+		return "", ErrInvalidCharset
+	}
+	if required > config.Length {
+		return "", ErrInvalidLength
+	}
+
+	password := make([]rune, 0, config.Length)
+	for _, class := range classes {
+		for range class.min {
+			c, err := src.char(class.chars)
+			if err != nil {
+				return "", err
+			}
+			password = append(password, c)
 		}
-		if config.UseLower {
-			charset.WriteString(lowerChars)
+	}
+	for len(password) < config.Length {
+		c, err := src.char(charset)
+		if err != nil {
+			return "", err
 		}
-		if config.UseNumbers {
-			charset.WriteString(numberChars)
+		password = append(password, c)
+	}
+
+	// the guaranteed characters were placed first, shuffle them to uniformly random positions
+	if err := src.shuffle(password); err != nil {
+		return "", err
+	}
+	if config.MaxRepeats > 0 {
+		if err := limitRuns(password, config.MaxRepeats, classes, charset, src); err != nil {
+			return "", err
 		}
-		if config.UseSpecial {
-			charset.WriteString(specialChars)
+	}
+
+	return string(password), nil
+}
+
+// limitRuns breaks runs longer than maxRepeats by redrawing the offending
+// character from its own class without the repeated one, so class counts hold
+func limitRuns(password []rune, maxRepeats int, classes []charClass, charset []rune, src *randomSource) error {
+	run := 1
+	for i := 1; i < len(password); i++ {
+		if password[i] != password[i-1] {
+			run = 1
+			continue
+		}
+		if run++; run <= maxRepeats {
+			continue
+		}
+		pool := charset
+		for _, class := range classes {
+			if slices.Contains(class.chars, password[i]) {
+				pool = class.chars
+				break
+			}
+		}
+		pool = slices.DeleteFunc(slices.Clone(pool), func(r rune) bool { return r == password[i-1] })
+		if len(pool) == 0 {
+			pool = slices.DeleteFunc(slices.Clone(charset), func(r rune) bool { return r == password[i-1] })
 		}
+		if len(pool) == 0 {
+			return fmt.Errorf("%w: max repeats need at least two distinct characters", ErrInvalidCharset)
+		}
+		c, err := src.char(pool)
+		if err != nil {
+			return err
+		}
+		password[i] = c
+		run = 1
 	}
+	return nil
+}
 
-	if config.ExcludeChars != "" {
-		charsetStr := charset.String()
-		for _, c := range config.ExcludeChars {
-			charsetStr = strings.ReplaceAll(charsetStr, string(c), "")
+// charset returns the custom charset or the union of the enabled classes, after exclusions
+func (c GenerateConfig) charset(classes []charClass) []rune {
+	if c.CustomCharset != "" {
+		return []rune(excludeChars(c.CustomCharset, c.ExcludeChars))
+	}
+	var charset []rune
+	for _, class := range classes {
+		charset = append(charset, class.chars...)
+	}
+	return charset
+}
+
+// classes splits the enabled charset into character classes by Unicode
+// category, after exclusions. Classes without characters are dropped.
+func (c GenerateConfig) classes() []charClass {
+	var upper, lower, numbers, special string
+	if c.CustomCharset != "" {
+		var u, l, n, s strings.Builder
+		for _, r := range c.CustomCharset {
+			switch {
+			case unicode.IsUpper(r):
+				u.WriteRune(r)
+			case unicode.IsLower(r):
+				l.WriteRune(r)
+			case unicode.IsNumber(r):
+				n.WriteRune(r)
+			default:
+				s.WriteRune(r)
+			}
 		}
-		charset.Reset()
-		charset.WriteString(charsetStr)
+		upper, lower, numbers, special = u.String(), l.String(), n.String(), s.String()
+	} else {
+		upper, lower, numbers, special = upperChars, lowerChars, numberChars, specialChars
 	}
 
-	if charset.Len() == 0 {
-		return "", ErrInvalidCharset
+	var classes []charClass
+	for _, class := range []struct {
+		use   bool
+		chars string
+		min   int
+	}{
+		{c.UseUpper, upper, c.MinUpper},
+		{c.UseLower, lower, c.MinLower},
+		{c.UseNumbers, numbers, c.MinNumbers},
+		{c.UseSpecial, special, c.MinSpecial},
+	} {
+		chars := excludeChars(class.chars, c.ExcludeChars)
+		if class.use && chars != "" {
+			classes = append(classes, charClass{chars: []rune(chars), min: max(class.min, 0)})
+		}
 	}
+	return classes
+}
 
-	var password strings.Builder
-	charsetStr := charset.String()
-	charsetLength := big.NewInt(int64(len(charsetStr)))
+func excludeChars(charset, exclude string) string {
+	for _, c := range exclude {
+		charset = strings.ReplaceAll(charset, string(c), "")
+	}
+	return charset
+}
 
-	for i := 0; i < config.Length; i++ {
-		n, err := rand.Int(rand.Reader, charsetLength)
+// GenerateBatch creates count passwords with the same options that are all distinct
+func GenerateBatch(count int, options ...GenerateOption) ([]string, error) {
+	if count < 0 {
+		return nil, ErrInvalidCount
+	}
+	config := newGenerateConfig(options)
+	src := newRandomSource(config.Rand)
+	passwords := make([]string, 0, count)
+	seen := make(map[string]bool, count)
+	for attempts := 0; len(passwords) < count; attempts++ {
+		// divide instead of multiplying count, which could overflow
+		if attempts/maxBatchAttemptsFactor >= count {
+			return nil, ErrBatchExhausted
+		}
+		password, err := generate(config, src)
 		if err != nil {
-			return "", err
+			return nil, err
+		}
+		if !seen[password] {
+			seen[password] = true
+			passwords = append(passwords, password)
 		}
-		password.WriteByte(charsetStr[n.Int64()])
 	}
-
-	return password.String(), nil
+	return passwords, nil
 }
 
 // MustGenerate is a helper that wraps Generate and panics if an error occurs
@@ -139,6 +366,13 @@ func GenerateWithoutSpecialOption() GenerateOption {
 	}
 }
 
+// GenerateWithMaxRepeatsOption limits runs of identical characters to count in ModeRandom
+func GenerateWithMaxRepeatsOption(count int) GenerateOption {
+	return func(c *GenerateConfig) {
+		c.MaxRepeats = count
+	}
+}
+
 // GenerateWithCustomCharsetOption sets a custom character set for password generation
 func GenerateWithCustomCharsetOption(charset string) GenerateOption {
 	return func(c *GenerateConfig) {
@@ -152,3 +386,75 @@ func GenerateWithExcludedCharsOption(chars string) GenerateOption {
 		c.ExcludeChars = chars
 	}
 }
+
+// GenerateWithMinUpperOption guarantees at least count uppercase letters
+func GenerateWithMinUpperOption(count int) GenerateOption {
+	return func(c *GenerateConfig) {
+		c.MinUpper = count
+		c.explicitMins |= minUpperSet
+	}
+}
+
+// GenerateWithMinLowerOption guarantees at least count lowercase letters
+func GenerateWithMinLowerOption(count int) GenerateOption {
+	return func(c *GenerateConfig) {
+		c.MinLower = count
+		c.explicitMins |= minLowerSet
+	}
+}
+
+// GenerateWithMinNumbersOption guarantees at least count numbers
+func GenerateWithMinNumbersOption(count int) GenerateOption {
+	return func(c *GenerateConfig) {
+		c.MinNumbers = count
+		c.explicitMins |= minNumbersSet
+	}
+}
+
+// GenerateWithMinSpecialOption guarantees at least count special characters
+func GenerateWithMinSpecialOption(count int) GenerateOption {
+	return func(c *GenerateConfig) {
+		c.MinSpecial = count
+		c.explicitMins |= minSpecialSet
+	}
+}
+
+// GenerateWithPronounceableOption builds passwords from random syllables, with
+// guaranteed uppercase letters placed at syllable starts and guaranteed numbers
+// and special characters appended. Pronounceable passwords have noticeably less
+// entropy per character than random ones, so prefer a longer length.
+func GenerateWithPronounceableOption() GenerateOption {
+	return func(c *GenerateConfig) {
+		c.Mode = ModePronounceable
+	}
+}
+
+// GenerateWithPatternOption fills a template such as XXXX-XXXX-9999. Length and
+// class options are ignored, exclusions apply. Placeholders:
+//
+//	X uppercase letter    x lowercase letter    9 digit
+//	A uppercase or digit  a lowercase or digit  ! special character
+//	* any character of the configured charset   \ escapes the next character
+//
+// Every other character is copied literally.
+func GenerateWithPatternOption(pattern string) GenerateOption {
+	return func(c *GenerateConfig) {
+		c.Mode = ModePattern
+		c.Pattern = pattern
+	}
+}
+
+// GenerateWithoutLookAlikesOption excludes the easily confused characters 0O1lI
+func GenerateWithoutLookAlikesOption() GenerateOption {
+	return func(c *GenerateConfig) {
+		c.ExcludeLookAlikes = true
+	}
+}
+
+// GenerateWithRandOption reads randomness from r instead of crypto/rand. Use a
+// seeded reader such as math/rand/v2's ChaCha8 for reproducible tests only.
+func GenerateWithRandOption(r io.Reader) GenerateOption {
+	return func(c *GenerateConfig) {
+		c.Rand = r
+	}
+}
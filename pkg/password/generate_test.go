@@ -1,6 +1,7 @@
 package password
 
 import (
+	"errors"
 	"strings"
 	"sync"
 	"testing"
@@ -152,3 +153,193 @@ func containsOnly(s, chars string) bool {
 	}
 	return true
 }
+
+func countAny(s, chars string) int {
+	var n int
+	for _, c := range s {
+		if strings.ContainsRune(chars, c) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestGenerateMinCounts(t *testing.T) {
+	i := is.New(t)
+	for range 200 {
+		password, err := Generate(
+			GenerateWithLengthOption(8),
+			GenerateWithMinNumbersOption(3),
+			GenerateWithMinSpecialOption(2),
+		)
+		i.NoErr(err)
+		i.Equal(len(password), 8)
+		i.True(countAny(password, numberChars) >= 3)
+		i.True(countAny(password, specialChars) >= 2)
+		i.True(countAny(password, upperChars) >= 1)
+		i.True(countAny(password, lowerChars) >= 1)
+	}
+}
+
+func TestGenerateMinCountsCustomCharset(t *testing.T) {
+	i := is.New(t)
+	for range 200 {
+		password, err := Generate(
+			GenerateWithLengthOption(6),
+			GenerateWithCustomCharsetOption("abcdef0123"),
+			GenerateWithMinNumbersOption(4),
+		)
+		i.NoErr(err)
+		i.True(countAny(password, numberChars) >= 4)
+		i.True(countAny(password, lowerChars) >= 1)
+	}
+}
+
+func TestGenerateMinCountsExceedLength(t *testing.T) {
+	i := is.New(t)
+	_, err := Generate(GenerateWithLengthOption(4), GenerateWithMinNumbersOption(3), GenerateWithMinUpperOption(2))
+	i.Equal(err, ErrInvalidLength)
+
+	// disabled classes do not count
+	_, err = Generate(GenerateWithLengthOption(4), GenerateWithMinNumbersOption(4), GenerateWithoutUpperOption(),
+		GenerateWithoutLowerOption(), GenerateWithoutSpecialOption())
+	i.NoErr(err)
+}
+
+func TestGenerateGuaranteedCharsArePlacedUniformly(t *testing.T) {
+	i := is.New(t)
+	var positions [4]int
+	for range 2000 {
+		password, err := Generate(
+			GenerateWithLengthOption(4),
+			GenerateWithCustomCharsetOption("abcdefghijklmnopqrstuvwxyz0"),
+			GenerateWithMinNumbersOption(1),
+		)
+		i.NoErr(err)
+		positions[strings.IndexByte(password, '0')]++
+	}
+	for _, n := range positions {
+		i.True(n > 300) // about 500 expected at every position
+	}
+}
+
+func TestGenerateOutputPassesVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		generate []GenerateOption
+		verify   []VerifyOption
+	}{
+		{"defaults", nil, nil},
+		{"short", []GenerateOption{GenerateWithLengthOption(8)}, nil},
+		{"counts", []GenerateOption{
+			GenerateWithLengthOption(10),
+			GenerateWithMinNumbersOption(2),
+			GenerateWithMinSpecialOption(3),
+		}, []VerifyOption{
+			VerifyWithMinNumbersOption(2),
+			VerifyWithMinSpecialOption(3),
+		}},
+		{"without special", []GenerateOption{GenerateWithoutSpecialOption()}, []VerifyOption{VerifyWithoutSpecialOption()}},
+		{"digits only", []GenerateOption{
+			GenerateWithLengthOption(8),
+			GenerateWithoutUpperOption(),
+			GenerateWithoutLowerOption(),
+			GenerateWithoutSpecialOption(),
+		}, []VerifyOption{
+			VerifyWithoutUppercaseOption(),
+			VerifyWithoutLowercaseOption(),
+			VerifyWithoutSpecialOption(),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			for range 500 {
+				password, err := Generate(tt.generate...)
+				is.NoErr(err)
+				is.NoErr(Verify(password, tt.verify...))
+			}
+		})
+	}
+}
+
+func TestGenerateBatchUnique(t *testing.T) {
+	i := is.New(t)
+	codes, err := GenerateBatch(500, GenerateWithPatternOption("999"))
+	i.NoErr(err)
+	i.Equal(len(codes), 500)
+	seen := make(map[string]bool)
+	for _, c := range codes {
+		i.True(!seen[c])
+		seen[c] = true
+	}
+}
+
+func TestGenerateBatchExhausted(t *testing.T) {
+	i := is.New(t)
+	_, err := GenerateBatch(11, GenerateWithPatternOption("9"))
+	i.Equal(err, ErrBatchExhausted)
+}
+
+func TestGenerateBatchCount(t *testing.T) {
+	i := is.New(t)
+	codes, err := GenerateBatch(0)
+	i.NoErr(err)
+	i.True(codes != nil)
+	i.Equal(len(codes), 0)
+
+	_, err = GenerateBatch(-1)
+	i.True(errors.Is(err, ErrInvalidCount))
+}
+
+func TestGenerateShortLength(t *testing.T) {
+	for _, length := range []int{1, 2, 3} {
+		t.Run(strings.Repeat("x", length), func(t *testing.T) {
+			is := is.New(t)
+			password, err := Generate(GenerateWithLengthOption(length))
+			is.NoErr(err)
+			is.Equal(len(password), length)
+		})
+	}
+
+	i := is.New(t)
+	// explicit minimums that do not fit still fail
+	_, err := Generate(GenerateWithLengthOption(3), GenerateWithMinNumbersOption(4))
+	i.True(errors.Is(err, ErrInvalidLength))
+
+	// explicit minimums are kept when the defaults are dropped
+	for range 50 {
+		password, err := Generate(GenerateWithLengthOption(3), GenerateWithMinNumbersOption(3))
+		i.NoErr(err)
+		i.Equal(countAny(password, numberChars), 3)
+	}
+
+	// defaults that fit still apply
+	for range 50 {
+		password, err := Generate(GenerateWithLengthOption(4))
+		i.NoErr(err)
+		i.Equal(countAny(password, upperChars), 1)
+		i.Equal(countAny(password, lowerChars), 1)
+		i.Equal(countAny(password, numberChars), 1)
+		i.Equal(countAny(password, specialChars), 1)
+	}
+}
+
+func TestGenerateMaxRepeats(t *testing.T) {
+	i := is.New(t)
+	for range 20 {
+		password, err := Generate(GenerateWithLengthOption(64), GenerateWithCustomCharsetOption("ab"), GenerateWithMaxRepeatsOption(1))
+		i.NoErr(err)
+		i.Equal(longestRun(password), 1)
+
+		// redrawn characters stay in their class
+		password, err = Generate(GenerateWithLengthOption(12), GenerateWithCustomCharsetOption("ab12"),
+			GenerateWithMinNumbersOption(6), GenerateWithMinLowerOption(6), GenerateWithMaxRepeatsOption(1))
+		i.NoErr(err)
+		i.Equal(longestRun(password), 1)
+		i.Equal(countAny(password, numberChars), 6)
+	}
+
+	_, err := Generate(GenerateWithCustomCharsetOption("a"), GenerateWithMaxRepeatsOption(2))
+	i.True(errors.Is(err, ErrInvalidCharset))
+}
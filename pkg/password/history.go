@@ -0,0 +1,279 @@
+package password
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+
+	"dario.lol/gotils/pkg/file"
+	"dario.lol/gotils/pkg/hash"
+)
+
+// minSimilarLength is the shortest similarity key worth comparing, shorter keys
+// such as the one of an all-digit password would match unrelated passwords
+const minSimilarLength = 4
+
+// HistoryEntry is a previously used password, stored as Argon2id PHC strings only
+type HistoryEntry struct {
+	// Hash is the hash of the normalized password
+	Hash string `json:"hash"`
+	// Similar is the hash of the password's similarity key, only stored with
+	// HistoryWithNearDuplicatesOption
+	Similar string `json:"similar,omitempty"`
+}
+
+// HistoryStore persists the password history of users, newest entry first
+type HistoryStore interface {
+	Load(user string) ([]HistoryEntry, error)
+	Save(user string, entries []HistoryEntry) error
+}
+
+// History rejects the reuse of a user's last Size passwords. Used costs one
+// Argon2id verification per stored entry, Size of them for a reused-free
+// password, so keep Size small or Params cheap on busy login paths. Adding to
+// a user's history is not atomic, callers should serialize password changes per user.
+type History struct {
+	Store  HistoryStore
+	Size   int
+	Params hash.Argon2idParams
+	// NearDuplicates also rejects passwords that only differ in case or digits,
+	// such as Summer2024! after Summer2023!. It stores a second hash of the
+	// lowercased password with digits collapsed, which has much less entropy:
+	// cracking it recovers the password up to case and digits.
+	NearDuplicates bool
+}
+
+// HistoryOption is a function that modifies History
+type HistoryOption func(*History)
+
+// NewHistory keeps the last size passwords of each user in store, a size below
+// one disables the history
+func NewHistory(store HistoryStore, size int, options ...HistoryOption) *History {
+	h := &History{
+		Store:  store,
+		Size:   size,
+		Params: hash.Argon2idDefaultParams,
+	}
+	for _, opt := range options {
+		opt(h)
+	}
+	return h
+}
+
+// HistoryWithNearDuplicatesOption also rejects near-duplicates, see History.NearDuplicates
+func HistoryWithNearDuplicatesOption() HistoryOption {
+	return func(h *History) {
+		h.NearDuplicates = true
+	}
+}
+
+// HistoryWithParamsOption hashes new entries with p (default: hash.Argon2idDefaultParams)
+func HistoryWithParamsOption(p hash.Argon2idParams) HistoryOption {
+	return func(h *History) {
+		h.Params = p
+	}
+}
+
+// Add records password as the user's newest password and drops entries beyond Size
+func (h *History) Add(user, password string) error {
+	if h.Size < 1 {
+		return nil
+	}
+	entries, err := h.Store.Load(user)
+	if err != nil {
+		return err
+	}
+
+	password = Normalize(password)
+	hasher := hash.NewArgon2idHasher(h.Params)
+	entry := HistoryEntry{}
+	if entry.Hash, err = HashPassword(password, hasher); err != nil {
+		return err
+	}
+	if key := similarityKey(password); h.NearDuplicates && len(key) >= minSimilarLength {
+		if entry.Similar, err = HashPassword(key, hasher); err != nil {
+			return err
+		}
+	}
+
+	entries = append([]HistoryEntry{entry}, entries...)
+	if len(entries) > h.Size {
+		entries = entries[:h.Size]
+	}
+	return h.Store.Save(user, entries)
+}
+
+// Used reports whether password or a near-duplicate of it is among the user's last Size passwords
+func (h *History) Used(user, password string) (bool, error) {
+	if h.Size < 1 {
+		return false, nil
+	}
+	entries, err := h.Store.Load(user)
+	if err != nil {
+		return false, err
+	}
+	if len(entries) > h.Size {
+		entries = entries[:h.Size]
+	}
+
+	password = Normalize(password)
+	key := similarityKey(password)
+	near := h.NearDuplicates && len(key) >= minSimilarLength
+	hasher := hash.NewArgon2idHasher(h.Params)
+	for _, entry := range entries {
+		// equal passwords share their similarity key, so an entry whose key
+		// differs cannot match exactly either and one verification suffices
+		var used bool
+		if near && entry.Similar != "" {
+			used, err = VerifyPassword(entry.Similar, key, hasher)
+		} else {
+			used, err = VerifyPassword(entry.Hash, password, hasher)
+		}
+		if err != nil {
+			return false, err
+		}
+		if used {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ForUser returns the PasswordHistory of user, for VerifyWithHistoryOption
+func (h *History) ForUser(user string) PasswordHistory {
+	return userHistory{history: h, user: user}
+}
+
+type userHistory struct {
+	history *History
+	user    string
+}
+
+func (u userHistory) Used(password string) (bool, error) {
+	return u.history.Used(u.user, password)
+}
+
+// similarityKey lowercases password and replaces every run of digits with a
+// single 0, so passwords only differing in case or numbers share a key
+func similarityKey(password string) string {
+	var (
+		key   strings.Builder
+		digit bool
+	)
+	for _, r := range strings.ToLower(password) {
+		if unicode.IsDigit(r) {
+			if !digit {
+				key.WriteByte('0')
+			}
+			digit = true
+			continue
+		}
+		digit = false
+		key.WriteRune(r)
+	}
+	return key.String()
+}
+
+// MemoryHistoryStore keeps histories in memory, it is safe for concurrent use
+type MemoryHistoryStore struct {
+	mu      sync.RWMutex
+	entries map[string][]HistoryEntry
+}
+
+// NewMemoryHistoryStore creates an empty in-memory history store
+func NewMemoryHistoryStore() *MemoryHistoryStore {
+	return &MemoryHistoryStore{entries: make(map[string][]HistoryEntry)}
+}
+
+func (s *MemoryHistoryStore) Load(user string) ([]HistoryEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]HistoryEntry(nil), s.entries[user]...), nil
+}
+
+func (s *MemoryHistoryStore) Save(user string, entries []HistoryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[user] = append([]HistoryEntry(nil), entries...)
+	return nil
+}
+
+// FileHistoryStore keeps the histories of all users in a single JSON file. It
+// is safe for concurrent use within one process.
+type FileHistoryStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileHistoryStore stores histories in the JSON file at path, which is
+// created on the first Save. Saves replace the file atomically and keep it
+// readable by the owner only.
+func NewFileHistoryStore(path string) *FileHistoryStore {
+	return &FileHistoryStore{path: path}
+}
+
+func (s *FileHistoryStore) Load(user string) ([]HistoryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	histories, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return histories[user], nil
+}
+
+func (s *FileHistoryStore) Save(user string, entries []HistoryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	histories, err := s.read()
+	if err != nil {
+		return err
+	}
+	histories[user] = entries
+	data, err := json.Marshal(histories)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, data)
+}
+
+// writeFileAtomic writes data to a temporary 0600 file next to path and renames
+// it over path, so a crash leaves either the old or the new file behind
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *FileHistoryStore) read() (map[string][]HistoryEntry, error) {
+	histories, err := file.ReadJson[map[string][]HistoryEntry](s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return make(map[string][]HistoryEntry), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if histories == nil {
+		histories = make(map[string][]HistoryEntry)
+	}
+	return histories, nil
+}
@@ -0,0 +1,168 @@
+package password
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"dario.lol/gotils/pkg/hash"
+	"github.com/matryer/is"
+)
+
+var testHistoryParams = hash.Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, KeyLen: 16}
+
+func newTestHistory(store HistoryStore, size int, options ...HistoryOption) *History {
+	return NewHistory(store, size, append([]HistoryOption{HistoryWithParamsOption(testHistoryParams)}, options...)...)
+}
+
+func TestHistoryUsed(t *testing.T) {
+	i := is.New(t)
+	exact := newTestHistory(NewMemoryHistoryStore(), 3)
+	i.NoErr(exact.Add("alice", "Summer2023!"))
+	near := newTestHistory(NewMemoryHistoryStore(), 3, HistoryWithNearDuplicatesOption())
+	i.NoErr(near.Add("alice", "Summer2023!"))
+
+	tests := []struct {
+		name      string
+		user      string
+		password  string
+		wantExact bool
+		wantNear  bool
+	}{
+		{"exact", "alice", "Summer2023!", true, true},
+		{"trailing digit changed", "alice", "Summer2024!", false, true},
+		{"case changed", "alice", "sUMMER2023!", false, true},
+		{"digits added", "alice", "Summer20231!", false, true},
+		{"different", "alice", "Winter2023!", false, false},
+		{"other user", "bob", "Summer2023!", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			used, err := exact.Used(tt.user, tt.password)
+			is.NoErr(err)
+			is.Equal(used, tt.wantExact)
+			used, err = near.Used(tt.user, tt.password)
+			is.NoErr(err)
+			is.Equal(used, tt.wantNear)
+		})
+	}
+}
+
+func TestHistoryStoresHashesOnly(t *testing.T) {
+	i := is.New(t)
+	store := NewMemoryHistoryStore()
+	i.NoErr(newTestHistory(store, 3).Add("alice", "Summer2023!"))
+	i.NoErr(newTestHistory(store, 3, HistoryWithNearDuplicatesOption()).Add("alice", "Summer2024!"))
+
+	entries, err := store.Load("alice")
+	i.NoErr(err)
+	i.Equal(len(entries), 2)
+	i.True(strings.HasPrefix(entries[0].Hash, "$argon2id$"))
+	i.True(strings.HasPrefix(entries[0].Similar, "$argon2id$"))
+	i.True(!strings.Contains(entries[0].Hash, "Summer"))
+	// the low-entropy similarity hash is only stored on request
+	i.Equal(entries[1].Similar, "")
+}
+
+func TestHistorySize(t *testing.T) {
+	i := is.New(t)
+	store := NewMemoryHistoryStore()
+	h := newTestHistory(store, 2)
+	for _, pw := range []string{"first-Password", "second-Password", "third-Password"} {
+		i.NoErr(h.Add("alice", pw))
+	}
+
+	entries, err := store.Load("alice")
+	i.NoErr(err)
+	i.Equal(len(entries), 2)
+
+	used, err := h.Used("alice", "first-Password")
+	i.NoErr(err)
+	i.True(!used)
+	used, err = h.Used("alice", "third-Password")
+	i.NoErr(err)
+	i.True(used)
+}
+
+func TestHistoryDisabled(t *testing.T) {
+	i := is.New(t)
+	store := NewMemoryHistoryStore()
+	h := newTestHistory(store, 0)
+	i.NoErr(h.Add("alice", "Summer2023!"))
+	used, err := h.Used("alice", "Summer2023!")
+	i.NoErr(err)
+	i.True(!used)
+	entries, err := store.Load("alice")
+	i.NoErr(err)
+	i.Equal(len(entries), 0)
+}
+
+func TestHistoryShortSimilarityKey(t *testing.T) {
+	i := is.New(t)
+	h := newTestHistory(NewMemoryHistoryStore(), 3, HistoryWithNearDuplicatesOption())
+	i.NoErr(h.Add("alice", "12345678"))
+	used, err := h.Used("alice", "87654321")
+	i.NoErr(err)
+	i.True(!used)
+}
+
+func TestHistoryNormalizes(t *testing.T) {
+	i := is.New(t)
+	h := newTestHistory(NewMemoryHistoryStore(), 3)
+	i.NoErr(h.Add("alice", "caf\u00e9-Password"))
+	used, err := h.Used("alice", "cafe\u0301-Password")
+	i.NoErr(err)
+	i.True(used)
+}
+
+func TestHistoryVerify(t *testing.T) {
+	i := is.New(t)
+	p := Policy{MinLength: 8, HistorySize: 3}
+	h := newTestHistory(NewMemoryHistoryStore(), p.HistorySize, HistoryWithNearDuplicatesOption())
+	i.NoErr(h.Add("alice", "Summer2023!"))
+
+	err := p.Verify("Summer2024!", VerifyWithHistoryOption(h.ForUser("alice")))
+	i.True(errors.Is(err, ErrReusedPassword))
+	i.NoErr(p.Verify("Summer2024!", VerifyWithHistoryOption(h.ForUser("bob"))))
+}
+
+func TestFileHistoryStore(t *testing.T) {
+	i := is.New(t)
+	path := filepath.Join(t.TempDir(), "history.json")
+	store := NewFileHistoryStore(path)
+
+	entries, err := store.Load("alice")
+	i.NoErr(err)
+	i.Equal(len(entries), 0)
+
+	h := newTestHistory(store, 3)
+	i.NoErr(h.Add("alice", "Summer2023!"))
+	i.NoErr(h.Add("bob", "Winter2023!"))
+
+	// a new store reads the persisted file
+	h = newTestHistory(NewFileHistoryStore(path), 3)
+	used, err := h.Used("alice", "Summer2023!")
+	i.NoErr(err)
+	i.True(used)
+	used, err = h.Used("bob", "Summer2023!")
+	i.NoErr(err)
+	i.True(!used)
+
+	info, err := os.Stat(path)
+	i.NoErr(err)
+	i.Equal(info.Mode().Perm(), os.FileMode(0o600))
+	tmp, err := filepath.Glob(filepath.Join(filepath.Dir(path), "*.tmp"))
+	i.NoErr(err)
+	i.Equal(len(tmp), 0)
+}
+
+func TestFileHistoryStoreMalformed(t *testing.T) {
+	i := is.New(t)
+	path := filepath.Join(t.TempDir(), "history.json")
+	i.NoErr(os.WriteFile(path, []byte("not json"), 0600))
+	_, err := NewFileHistoryStore(path).Load("alice")
+	i.True(err != nil)
+}
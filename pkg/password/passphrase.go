@@ -0,0 +1,266 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"dario.lol/gotils/pkg/file"
+)
+
+//go:embed data/eff_large_wordlist.txt
+var effLargeWordlistData string
+
+//go:embed data/eff_small_wordlist.txt
+var effShortWordlistData string
+
+var (
+	ErrInvalidWordlist  = errors.New("invalid wordlist: at least two unique words required")
+	ErrInvalidWordCount = errors.New("invalid word count: at least one word required")
+)
+
+// Wordlist is a list of unique words passphrases are drawn from
+type Wordlist []string
+
+var (
+	// EFFLargeWordlist is the EFF long wordlist, 7776 words with about 12.9 bits of entropy each
+	EFFLargeWordlist = mustParseWordlist(effLargeWordlistData)
+	// EFFShortWordlist is the EFF short wordlist 2.0, 1296 words with about 10.3 bits of entropy each
+	EFFShortWordlist = mustParseWordlist(effShortWordlistData)
+)
+
+// ParseWordlist reads one word per line. Lines may start with diceware dice
+// rolls, as in the EFF lists. Empty lines and lines starting with # are
+// skipped, duplicates are dropped.
+func ParseWordlist(r io.Reader) (Wordlist, error) {
+	var (
+		words Wordlist
+		seen  = make(map[string]bool)
+	)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) == 2 && isDigits(fields[0]) {
+			fields = fields[1:]
+		}
+		if len(fields) != 1 {
+			return nil, fmt.Errorf("%w: line %d must hold a single word", ErrInvalidWordlist, line)
+		}
+		if word := fields[0]; !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(words) < 2 {
+		return nil, ErrInvalidWordlist
+	}
+	return words, nil
+}
+
+// LoadWordlist reads a wordlist file, see ParseWordlist
+func LoadWordlist(path string) (Wordlist, error) {
+	data, err := file.ReadString(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWordlist(strings.NewReader(data))
+}
+
+func mustParseWordlist(data string) Wordlist {
+	w, err := ParseWordlist(strings.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+// unique returns the words of w without duplicates, in their original order
+func (w Wordlist) unique() Wordlist {
+	seen := make(map[string]bool, len(w))
+	words := make(Wordlist, 0, len(w))
+	for _, word := range w {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// Entropy returns the bits of entropy of a single word drawn from the list
+func (w Wordlist) Entropy() float64 {
+	return math.Log2(float64(len(w)))
+}
+
+// PassphraseConfig holds passphrase generation settings
+type PassphraseConfig struct {
+	Words      int
+	Separator  string
+	Capitalize bool
+	AddNumber  bool
+	AddSymbol  bool
+	Wordlist   Wordlist
+	// Rand is the source of randomness, crypto/rand if nil
+	Rand io.Reader
+}
+
+// PassphraseOption is a function that modifies PassphraseConfig
+type PassphraseOption func(*PassphraseConfig)
+
+func newPassphraseConfig(options []PassphraseOption) (PassphraseConfig, error) {
+	config := PassphraseConfig{
+		Words:     6,
+		Separator: " ",
+		Wordlist:  EFFLargeWordlist,
+	}
+	for _, opt := range options {
+		opt(&config)
+	}
+	config.Wordlist = config.Wordlist.unique()
+	if config.Words < 1 {
+		return config, ErrInvalidWordCount
+	}
+	if len(config.Wordlist) < 2 {
+		return config, ErrInvalidWordlist
+	}
+	return config, nil
+}
+
+// GeneratePassphrase creates a diceware passphrase of random words, by default
+// six words from the EFF long wordlist separated by spaces. Some EFF words
+// contain dashes, so a dash separator makes the words ambiguous to split.
+func GeneratePassphrase(options ...PassphraseOption) (string, error) {
+	config, err := newPassphraseConfig(options)
+	if err != nil {
+		return "", err
+	}
+
+	src := newRandomSource(config.Rand)
+	words := make([]string, config.Words)
+	for i := range words {
+		n, err := src.index(len(config.Wordlist))
+		if err != nil {
+			return "", err
+		}
+		words[i] = config.Wordlist[n]
+		if config.Capitalize {
+			words[i] = capitalize(words[i])
+		}
+	}
+
+	for _, insert := range []struct {
+		enabled bool
+		chars   string
+	}{
+		{config.AddNumber, numberChars},
+		{config.AddSymbol, specialChars},
+	} {
+		if !insert.enabled {
+			continue
+		}
+		word, err := src.index(len(words))
+		if err != nil {
+			return "", err
+		}
+		c, err := src.char([]rune(insert.chars))
+		if err != nil {
+			return "", err
+		}
+		words[word] += string(c)
+	}
+
+	return strings.Join(words, config.Separator), nil
+}
+
+// MustGeneratePassphrase is a helper that wraps GeneratePassphrase and panics if an error occurs
+func MustGeneratePassphrase(options ...PassphraseOption) string {
+	passphrase, err := GeneratePassphrase(options...)
+	if err != nil {
+		panic(err)
+	}
+	return passphrase
+}
+
+// PassphraseEntropy returns the bits of entropy of passphrases generated with options,
+// assuming the attacker knows the wordlist and settings
+func PassphraseEntropy(options ...PassphraseOption) (float64, error) {
+	config, err := newPassphraseConfig(options)
+	if err != nil {
+		return 0, err
+	}
+	// the inserted character and the word it is appended to are both random
+	bits := float64(config.Words) * config.Wordlist.Entropy()
+	if config.AddNumber {
+		bits += math.Log2(float64(len(numberChars) * config.Words))
+	}
+	if config.AddSymbol {
+		bits += math.Log2(float64(len(specialChars) * config.Words))
+	}
+	return bits, nil
+}
+
+func capitalize(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(r)) + word[size:]
+}
+
+// PassphraseWithWordsOption sets the number of words (default: 6)
+func PassphraseWithWordsOption(count int) PassphraseOption {
+	return func(c *PassphraseConfig) {
+		c.Words = count
+	}
+}
+
+// PassphraseWithSeparatorOption sets the string between words (default: " ")
+func PassphraseWithSeparatorOption(separator string) PassphraseOption {
+	return func(c *PassphraseConfig) {
+		c.Separator = separator
+	}
+}
+
+// PassphraseWithCapitalizeOption capitalizes the first letter of every word
+func PassphraseWithCapitalizeOption() PassphraseOption {
+	return func(c *PassphraseConfig) {
+		c.Capitalize = true
+	}
+}
+
+// PassphraseWithNumberOption appends a random digit to a random word
+func PassphraseWithNumberOption() PassphraseOption {
+	return func(c *PassphraseConfig) {
+		c.AddNumber = true
+	}
+}
+
+// PassphraseWithSymbolOption appends a random special character to a random word
+func PassphraseWithSymbolOption() PassphraseOption {
+	return func(c *PassphraseConfig) {
+		c.AddSymbol = true
+	}
+}
+
+// PassphraseWithWordlistOption draws words from w instead of the EFF long wordlist, duplicates are ignored
+func PassphraseWithWordlistOption(w Wordlist) PassphraseOption {
+	return func(c *PassphraseConfig) {
+		c.Wordlist = w
+	}
+}
+
+// PassphraseWithRandOption reads randomness from r instead of crypto/rand
+func PassphraseWithRandOption(r io.Reader) PassphraseOption {
+	return func(c *PassphraseConfig) {
+		c.Rand = r
+	}
+}
@@ -0,0 +1,113 @@
+package password
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/matryer/is"
+)
+
+func TestEmbeddedWordlists(t *testing.T) {
+	i := is.New(t)
+	i.Equal(len(EFFLargeWordlist), 7776)
+	i.Equal(len(EFFShortWordlist), 1296)
+	i.Equal(EFFLargeWordlist[0], "abacus")
+	i.True(math.Abs(EFFLargeWordlist.Entropy()-12.925) < 0.001)
+}
+
+func TestGeneratePassphraseDefault(t *testing.T) {
+	i := is.New(t)
+	passphrase, err := GeneratePassphrase()
+	i.NoErr(err)
+
+	words := strings.Split(passphrase, " ")
+	for _, w := range words {
+		i.True(slices.Contains(EFFLargeWordlist, w))
+	}
+	i.Equal(len(words), 6)
+}
+
+func TestGeneratePassphraseOptions(t *testing.T) {
+	i := is.New(t)
+	passphrase, err := GeneratePassphrase(
+		PassphraseWithWordsOption(4),
+		PassphraseWithSeparatorOption(" "),
+		PassphraseWithCapitalizeOption(),
+		PassphraseWithNumberOption(),
+		PassphraseWithSymbolOption(),
+		PassphraseWithWordlistOption(EFFShortWordlist),
+	)
+	i.NoErr(err)
+	words := strings.Split(passphrase, " ")
+	i.Equal(len(words), 4)
+	for _, w := range words {
+		i.True(unicode.IsUpper([]rune(w)[0]))
+	}
+	i.Equal(countAny(passphrase, numberChars), 1)
+	i.Equal(countAny(passphrase, specialChars), 1)
+	i.NoErr(Verify(passphrase))
+}
+
+func TestGeneratePassphraseCustomWordlist(t *testing.T) {
+	i := is.New(t)
+	path := filepath.Join(t.TempDir(), "words.txt")
+	i.NoErr(os.WriteFile(path, []byte("# fruit\n1 apfel\n2 birne\n\nkiwi\nkiwi\n"), 0o644))
+
+	w, err := LoadWordlist(path)
+	i.NoErr(err)
+	i.Equal(w, Wordlist{"apfel", "birne", "kiwi"})
+
+	passphrase, err := GeneratePassphrase(PassphraseWithWordlistOption(w), PassphraseWithSeparatorOption("."))
+	i.NoErr(err)
+	for _, word := range strings.Split(passphrase, ".") {
+		i.True(slices.Contains(w, word))
+	}
+}
+
+func TestParseWordlistInvalid(t *testing.T) {
+	i := is.New(t)
+	_, err := ParseWordlist(strings.NewReader("only\n"))
+	i.True(errors.Is(err, ErrInvalidWordlist))
+	_, err = ParseWordlist(strings.NewReader("two words\nother\n"))
+	i.True(errors.Is(err, ErrInvalidWordlist))
+}
+
+func TestGeneratePassphraseInvalid(t *testing.T) {
+	i := is.New(t)
+	_, err := GeneratePassphrase(PassphraseWithWordsOption(0))
+	i.Equal(err, ErrInvalidWordCount)
+	_, err = GeneratePassphrase(PassphraseWithWordlistOption(Wordlist{"one"}))
+	i.Equal(err, ErrInvalidWordlist)
+	_, err = GeneratePassphrase(PassphraseWithWordlistOption(Wordlist{"one", "one"}))
+	i.Equal(err, ErrInvalidWordlist)
+}
+
+func TestPassphraseEntropy(t *testing.T) {
+	i := is.New(t)
+	bits, err := PassphraseEntropy()
+	i.NoErr(err)
+	i.True(math.Abs(bits-6*math.Log2(7776)) < 1e-9)
+
+	bits, err = PassphraseEntropy(PassphraseWithWordsOption(4), PassphraseWithWordlistOption(EFFShortWordlist), PassphraseWithNumberOption())
+	i.NoErr(err)
+	i.True(math.Abs(bits-(4*math.Log2(1296)+math.Log2(40))) < 1e-9)
+
+	// duplicate words add no entropy
+	bits, err = PassphraseEntropy(PassphraseWithWordsOption(2), PassphraseWithWordlistOption(Wordlist{"apfel", "birne", "apfel", "apfel"}))
+	i.NoErr(err)
+	i.True(math.Abs(bits-2) < 1e-9)
+}
+
+func TestMustGeneratePassphrasePanic(t *testing.T) {
+	i := is.New(t)
+	defer func() {
+		i.True(recover() != nil)
+	}()
+	MustGeneratePassphrase(PassphraseWithWordsOption(-1))
+}
@@ -0,0 +1,57 @@
+package password
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidPattern = errors.New("invalid pattern: must not be empty or end with an escape")
+
+func generatePattern(config GenerateConfig, src *randomSource) (string, error) {
+	if config.Pattern == "" {
+		return "", ErrInvalidPattern
+	}
+	placeholders := map[rune]string{
+		'X': upperChars,
+		'x': lowerChars,
+		'9': numberChars,
+		'A': upperChars + numberChars,
+		'a': lowerChars + numberChars,
+		'!': specialChars,
+		'*': string(config.charset(config.classes())),
+	}
+
+	var (
+		password strings.Builder
+		escaped  bool
+	)
+	for _, r := range config.Pattern {
+		if escaped {
+			password.WriteRune(r)
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		chars, ok := placeholders[r]
+		if !ok {
+			password.WriteRune(r)
+			continue
+		}
+		chars = excludeChars(chars, config.ExcludeChars)
+		if chars == "" {
+			return "", ErrInvalidCharset
+		}
+		c, err := src.char([]rune(chars))
+		if err != nil {
+			return "", err
+		}
+		password.WriteRune(c)
+	}
+	if escaped {
+		return "", ErrInvalidPattern
+	}
+	return password.String(), nil
+}
@@ -0,0 +1,50 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestGeneratePattern(t *testing.T) {
+	i := is.New(t)
+	for range 100 {
+		code, err := Generate(GenerateWithPatternOption("XXXX-xxxx-9999"))
+		i.NoErr(err)
+		i.Equal(len(code), 14)
+		i.Equal(countAny(code[0:4], upperChars), 4)
+		i.Equal(code[4], byte('-'))
+		i.Equal(countAny(code[5:9], lowerChars), 4)
+		i.Equal(countAny(code[10:], numberChars), 4)
+	}
+}
+
+func TestGeneratePatternPlaceholders(t *testing.T) {
+	i := is.New(t)
+	code, err := Generate(GenerateWithPatternOption(`Aa!*\X\\`), GenerateWithCustomCharsetOption("q"))
+	i.NoErr(err)
+	i.True(strings.ContainsAny(code[0:1], upperChars+numberChars))
+	i.True(strings.ContainsAny(code[1:2], lowerChars+numberChars))
+	i.True(strings.ContainsAny(code[2:3], specialChars))
+	i.Equal(code[3:], `qX\`)
+}
+
+func TestGeneratePatternWithoutLookAlikes(t *testing.T) {
+	i := is.New(t)
+	for range 200 {
+		code, err := Generate(GenerateWithPatternOption("AAAAAAAAAA-aaaaaaaaaa"), GenerateWithoutLookAlikesOption())
+		i.NoErr(err)
+		i.True(!strings.ContainsAny(code, lookAlikeChars))
+	}
+}
+
+func TestGeneratePatternInvalid(t *testing.T) {
+	i := is.New(t)
+	_, err := Generate(GenerateWithPatternOption(""))
+	i.Equal(err, ErrInvalidPattern)
+	_, err = Generate(GenerateWithPatternOption(`XX\`))
+	i.Equal(err, ErrInvalidPattern)
+	_, err = Generate(GenerateWithPatternOption("9"), GenerateWithExcludedCharsOption(numberChars))
+	i.Equal(err, ErrInvalidCharset)
+}
@@ -0,0 +1,170 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+
+	"dario.lol/gotils/pkg/file"
+)
+
+const (
+	// maxPolicyGenerateAttempts bounds how often Policy.Generate redraws passwords
+	// that contain a forbidden substring or score too low
+	maxPolicyGenerateAttempts = 1000
+	defaultGenerateLength     = 16
+)
+
+var (
+	ErrInvalidPolicy       = errors.New("invalid password policy")
+	ErrPolicyUnsatisfiable = errors.New("failed to generate a password satisfying the policy")
+)
+
+// PasswordHistory reports whether a password was used before
+type PasswordHistory interface {
+	Used(password string) (bool, error)
+}
+
+// Policy is a serializable set of password rules that drives both Verify and
+// Generate. Its yaml tags mirror the json tags, so YAML decoders read the same
+// keys. Zero values disable a rule, except MaxLength where zero means no limit.
+type Policy struct {
+	MinLength           int      `json:"min_length" yaml:"min_length"`
+	MaxLength           int      `json:"max_length,omitempty" yaml:"max_length,omitempty"`
+	MinUppercase        int      `json:"min_uppercase,omitempty" yaml:"min_uppercase,omitempty"`
+	MinLowercase        int      `json:"min_lowercase,omitempty" yaml:"min_lowercase,omitempty"`
+	MinNumbers          int      `json:"min_numbers,omitempty" yaml:"min_numbers,omitempty"`
+	MinSpecial          int      `json:"min_special,omitempty" yaml:"min_special,omitempty"`
+	MaxRepeats          int      `json:"max_repeats,omitempty" yaml:"max_repeats,omitempty"`
+	ForbiddenSubstrings []string `json:"forbidden_substrings,omitempty" yaml:"forbidden_substrings,omitempty"`
+	// HistorySize is the number of previous passwords that may not be reused,
+	// enforced by the History created with Policy.History
+	HistorySize int `json:"history_size,omitempty" yaml:"history_size,omitempty"`
+	MinScore    int `json:"min_score,omitempty" yaml:"min_score,omitempty"`
+}
+
+// DefaultPolicy matches the defaults of Verify
+var DefaultPolicy = Policy{
+	MinLength:    8,
+	MaxLength:    128,
+	MinUppercase: 1,
+	MinLowercase: 1,
+	MinNumbers:   1,
+	MinSpecial:   1,
+}
+
+// LoadPolicy reads a policy from a JSON file
+func LoadPolicy(path string) (Policy, error) {
+	p, err := file.ReadJson[Policy](path)
+	if err != nil {
+		return Policy{}, err
+	}
+	return p, p.Validate()
+}
+
+// Validate reports whether the policy's rules are consistent and can be satisfied
+func (p Policy) Validate() error {
+	if p.MinLength < 0 || p.MaxLength < 0 || p.MinUppercase < 0 || p.MinLowercase < 0 ||
+		p.MinNumbers < 0 || p.MinSpecial < 0 || p.MaxRepeats < 0 || p.HistorySize < 0 {
+		return fmt.Errorf("%w: values must not be negative", ErrInvalidPolicy)
+	}
+	if p.MinScore < 0 || p.MinScore > 4 {
+		return fmt.Errorf("%w: min score must be between 0 and 4", ErrInvalidPolicy)
+	}
+	if p.MaxLength > 0 && p.MinLength > p.MaxLength {
+		return fmt.Errorf("%w: min length %d exceeds max length %d", ErrInvalidPolicy, p.MinLength, p.MaxLength)
+	}
+	if classes := p.classMinimum(); p.MaxLength > 0 && classes > p.MaxLength {
+		return fmt.Errorf("%w: required characters %d exceed max length %d", ErrInvalidPolicy, classes, p.MaxLength)
+	}
+	return nil
+}
+
+func (p Policy) classMinimum() int {
+	return p.MinUppercase + p.MinLowercase + p.MinNumbers + p.MinSpecial
+}
+
+// Verify checks password against the policy. options are applied after the
+// policy, for example to forbid the user's name or check their history.
+func (p Policy) Verify(password string, options ...VerifyOption) error {
+	return Verify(password, append([]VerifyOption{VerifyWithPolicyOption(p)}, options...)...)
+}
+
+// History returns a History of store that remembers HistorySize passwords per user
+func (p Policy) History(store HistoryStore, options ...HistoryOption) *History {
+	return NewHistory(store, p.HistorySize, options...)
+}
+
+// Generate creates a random password that satisfies the policy. Length, class
+// counts and MaxRepeats are guaranteed by construction. A password containing
+// a forbidden substring or scoring below MinScore is drawn again, which is
+// rare at the generated lengths. options are applied after the policy, if they
+// contradict it Generate fails with ErrPolicyUnsatisfiable.
+func (p Policy) Generate(options ...GenerateOption) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+	options = append([]GenerateOption{
+		GenerateWithLengthOption(p.generateLength()),
+		GenerateWithMinUpperOption(p.MinUppercase),
+		GenerateWithMinLowerOption(p.MinLowercase),
+		GenerateWithMinNumbersOption(p.MinNumbers),
+		GenerateWithMinSpecialOption(p.MinSpecial),
+		GenerateWithMaxRepeatsOption(p.MaxRepeats),
+	}, options...)
+	for range maxPolicyGenerateAttempts {
+		password, err := Generate(options...)
+		if err != nil {
+			return "", err
+		}
+		err = p.Verify(password)
+		if err == nil {
+			return password, nil
+		}
+		if !redrawable(err) {
+			return "", fmt.Errorf("%w: %v", ErrPolicyUnsatisfiable, err)
+		}
+	}
+	return "", ErrPolicyUnsatisfiable
+}
+
+// redrawable reports whether err only violates rules that a fresh random
+// password may satisfy, other violations mean the options contradict the policy
+func redrawable(err error) bool {
+	var pe *PolicyError
+	if !errors.As(err, &pe) {
+		return false
+	}
+	for _, v := range pe.Violations {
+		if v.Code != CodeForbiddenSubstring && v.Code != CodeTooGuessable {
+			return false
+		}
+	}
+	return true
+}
+
+func (p Policy) generateLength() int {
+	length := max(p.MinLength, defaultGenerateLength, p.classMinimum())
+	if p.MaxLength > 0 {
+		length = min(length, p.MaxLength)
+	}
+	return length
+}
+
+// VerifyWithPolicyOption replaces all rules with those of p
+func VerifyWithPolicyOption(p Policy) VerifyOption {
+	return func(c *VerifyConfig) {
+		c.MinLength = p.MinLength
+		c.MaxLength = p.MaxLength
+		c.RequireUppercase = p.MinUppercase > 0
+		c.RequireLowercase = p.MinLowercase > 0
+		c.RequireNumbers = p.MinNumbers > 0
+		c.RequireSpecial = p.MinSpecial > 0
+		c.MinUppercase = p.MinUppercase
+		c.MinLowercase = p.MinLowercase
+		c.MinNumbers = p.MinNumbers
+		c.MinSpecial = p.MinSpecial
+		c.MaxRepeats = p.MaxRepeats
+		c.ForbiddenSubstrings = append(c.ForbiddenSubstrings, p.ForbiddenSubstrings...)
+		c.MinScore = p.MinScore
+	}
+}
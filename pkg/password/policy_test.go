@@ -0,0 +1,162 @@
+package password
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type fakeHistory map[string]bool
+
+func (h fakeHistory) Used(password string) (bool, error) {
+	return h[password], nil
+}
+
+var tenantPolicy = Policy{
+	MinLength:           12,
+	MaxLength:           64,
+	MinUppercase:        1,
+	MinLowercase:        1,
+	MinNumbers:          2,
+	MinSpecial:          1,
+	MaxRepeats:          2,
+	ForbiddenSubstrings: []string{"acme"},
+	HistorySize:         5,
+	MinScore:            3,
+}
+
+func TestPolicyJSON(t *testing.T) {
+	i := is.New(t)
+	data, err := json.Marshal(tenantPolicy)
+	i.NoErr(err)
+
+	var decoded Policy
+	i.NoErr(json.Unmarshal(data, &decoded))
+	i.Equal(decoded, tenantPolicy)
+
+	data, err = json.Marshal(Policy{MinLength: 10})
+	i.NoErr(err)
+	i.Equal(string(data), `{"min_length":10}`)
+}
+
+func TestPolicyYAMLTags(t *testing.T) {
+	i := is.New(t)
+	typ := reflect.TypeFor[Policy]()
+	for n := range typ.NumField() {
+		field := typ.Field(n)
+		i.True(field.Tag.Get("yaml") != "")
+		i.Equal(field.Tag.Get("yaml"), field.Tag.Get("json"))
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	i := is.New(t)
+	path := filepath.Join(t.TempDir(), "policy.json")
+	i.NoErr(os.WriteFile(path, []byte(`{"min_length": 10, "min_numbers": 2, "forbidden_substrings": ["acme"]}`), 0o644))
+
+	p, err := LoadPolicy(path)
+	i.NoErr(err)
+	i.Equal(p.MinLength, 10)
+	i.Equal(p.MinNumbers, 2)
+	i.Equal(p.ForbiddenSubstrings, []string{"acme"})
+
+	i.NoErr(os.WriteFile(path, []byte(`{"min_length": 10, "max_length": 5}`), 0o644))
+	_, err = LoadPolicy(path)
+	i.True(errors.Is(err, ErrInvalidPolicy))
+}
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		valid  bool
+	}{
+		{"default", DefaultPolicy, true},
+		{"tenant", tenantPolicy, true},
+		{"zero", Policy{}, true},
+		{"negative", Policy{MinNumbers: -1}, false},
+		{"min above max", Policy{MinLength: 10, MaxLength: 8}, false},
+		{"classes above max", Policy{MaxLength: 3, MinNumbers: 2, MinSpecial: 2}, false},
+		{"score out of range", Policy{MinScore: 5}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			err := tt.policy.Validate()
+			is.Equal(err == nil, tt.valid)
+			if !tt.valid {
+				is.True(errors.Is(err, ErrInvalidPolicy))
+			}
+		})
+	}
+}
+
+func TestPolicyVerify(t *testing.T) {
+	i := is.New(t)
+	i.NoErr(tenantPolicy.Verify("Vx7#kq2Lm9!pZ"))
+
+	err := tenantPolicy.Verify("Acme7#kq2Lm9!p")
+	i.True(errors.Is(err, ErrForbiddenSubstring))
+
+	err = tenantPolicy.Verify("Vx7#kqqqLm!pZw")
+	i.True(errors.Is(err, ErrTooManyRepeats))
+	i.True(errors.Is(err, ErrMissingNumber))
+	var pe *PolicyError
+	i.True(errors.As(err, &pe))
+	i.Equal(pe.Violations[0], Violation{Code: CodeMissingNumber, Threshold: 2, Actual: 1})
+	i.Equal(pe.Violations[1], Violation{Code: CodeTooManyRepeats, Threshold: 2, Actual: 3})
+	i.Equal(pe.Violations[0].Error(), "password must contain at least 2 numbers")
+
+	err = tenantPolicy.Verify("Vx7#kq2Lm9!pZ", VerifyWithForbiddenSubstringsOption("LM9"))
+	i.True(errors.Is(err, ErrForbiddenSubstring))
+
+	err = tenantPolicy.Verify("Vx7#kq2Lm9!pZ", VerifyWithHistoryOption(fakeHistory{"Vx7#kq2Lm9!pZ": true}))
+	i.True(errors.Is(err, ErrReusedPassword))
+}
+
+func TestPolicyZeroMaxLength(t *testing.T) {
+	i := is.New(t)
+	err := Policy{MinLength: 4}.Verify(string(make([]byte, 1000)))
+	i.NoErr(err)
+}
+
+func TestPolicyGenerate(t *testing.T) {
+	policies := map[string]Policy{
+		"default": DefaultPolicy,
+		"tenant":  tenantPolicy,
+		"short":   {MinLength: 6, MaxLength: 6, MinNumbers: 3, MinSpecial: 2, MaxRepeats: 1},
+		"digits":  {MinLength: 20, MaxLength: 20, MinNumbers: 20, MaxRepeats: 1},
+	}
+	for name, p := range policies {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			for range 50 {
+				password, err := p.Generate()
+				is.NoErr(err)
+				is.NoErr(p.Verify(password))
+				is.True(len(password) >= max(p.MinLength, 6))
+			}
+		})
+	}
+}
+
+func TestPolicyHistory(t *testing.T) {
+	i := is.New(t)
+	h := tenantPolicy.History(NewMemoryHistoryStore(), HistoryWithParamsOption(testHistoryParams))
+	i.Equal(h.Size, tenantPolicy.HistorySize)
+	i.Equal(h.Params, testHistoryParams)
+}
+
+func TestPolicyGenerateUnsatisfiable(t *testing.T) {
+	i := is.New(t)
+	_, err := Policy{MinNumbers: 1}.Generate(GenerateWithoutNumbersOption())
+	i.True(errors.Is(err, ErrPolicyUnsatisfiable))
+
+	_, err = Policy{MinLength: 10, MaxLength: 5}.Generate()
+	i.True(errors.Is(err, ErrInvalidPolicy))
+}
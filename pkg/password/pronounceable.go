@@ -0,0 +1,126 @@
+package password
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	syllableOnsets = []string{
+		"b", "c", "d", "f", "g", "h", "j", "k", "l", "m", "n", "p", "r", "s", "t", "v", "w", "z",
+		"br", "ch", "cr", "dr", "fl", "fr", "gl", "gr", "kl", "pl", "pr", "sh", "sk", "sl", "sp", "st", "th", "tr",
+	}
+	syllableVowels = []string{"a", "e", "i", "o", "u", "ai", "au", "ea", "ee", "ie", "oa", "oo", "ou"}
+	// syllableCodas ends most syllables open, the empty entries keep that likely
+	syllableCodas = []string{"", "", "", "", "", "", "n", "r", "s", "l", "m", "t", "nd", "st"}
+)
+
+func generatePronounceable(config GenerateConfig, src *randomSource) (string, error) {
+	var suffixes []charClass
+	suffixLength := 0
+	for _, class := range []struct {
+		use   bool
+		chars string
+		min   int
+	}{
+		{config.UseNumbers, numberChars, config.MinNumbers},
+		{config.UseSpecial, specialChars, config.MinSpecial},
+	} {
+		chars := excludeChars(class.chars, config.ExcludeChars)
+		if class.use && chars != "" && class.min > 0 {
+			suffixes = append(suffixes, charClass{chars: []rune(chars), min: class.min})
+			suffixLength += class.min
+		}
+	}
+	letters := config.Length - suffixLength
+	if letters < 1 {
+		return "", ErrInvalidLength
+	}
+
+	onsets := filterParts(syllableOnsets, config.ExcludeChars)
+	vowels := filterParts(syllableVowels, config.ExcludeChars)
+	codas := filterParts(syllableCodas, config.ExcludeChars)
+	if len(onsets) == 0 || len(vowels) == 0 {
+		return "", ErrInvalidCharset
+	}
+
+	var (
+		word   []byte
+		starts []int
+	)
+	for len(word) < letters {
+		starts = append(starts, len(word))
+		for _, parts := range [][]string{onsets, vowels, codas} {
+			if len(parts) == 0 {
+				continue
+			}
+			n, err := src.index(len(parts))
+			if err != nil {
+				return "", err
+			}
+			word = append(word, parts[n]...)
+		}
+	}
+	word = word[:letters]
+
+	if config.UseUpper && config.MinUpper > 0 {
+		if err := capitalizeLetters(word, starts, config.MinUpper, config.ExcludeChars); err != nil {
+			return "", err
+		}
+	}
+
+	for _, suffix := range suffixes {
+		for range suffix.min {
+			c, err := src.char(suffix.chars)
+			if err != nil {
+				return "", err
+			}
+			word = utf8.AppendRune(word, c)
+		}
+	}
+	return string(word), nil
+}
+
+// capitalizeLetters uppercases count letters, preferring syllable starts so the
+// password stays readable
+func capitalizeLetters(word []byte, starts []int, count int, exclude string) error {
+	candidates := make([]int, 0, len(word))
+	for _, i := range starts {
+		if i < len(word) {
+			candidates = append(candidates, i)
+		}
+	}
+	for i := range word {
+		if !slices.Contains(starts, i) {
+			candidates = append(candidates, i)
+		}
+	}
+	for _, i := range candidates {
+		if count == 0 {
+			return nil
+		}
+		upper := byte(unicode.ToUpper(rune(word[i])))
+		if upper == word[i] || strings.IndexByte(exclude, upper) >= 0 {
+			continue
+		}
+		word[i] = upper
+		count--
+	}
+	if count > 0 {
+		return ErrInvalidLength
+	}
+	return nil
+}
+
+// filterParts drops syllable parts containing excluded characters
+func filterParts(parts []string, exclude string) []string {
+	var filtered []string
+	for _, p := range parts {
+		if p == "" || !strings.ContainsAny(p, exclude) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,66 @@
+package password
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/matryer/is"
+)
+
+func TestGeneratePronounceable(t *testing.T) {
+	i := is.New(t)
+	for range 200 {
+		password, err := Generate(GenerateWithPronounceableOption())
+		i.NoErr(err)
+		i.Equal(len(password), 16)
+		i.True(unicode.IsUpper(rune(password[0])))
+		i.True(strings.ContainsAny(password[14:15], numberChars))
+		i.True(strings.ContainsAny(password[15:], specialChars))
+		i.NoErr(Verify(password))
+	}
+}
+
+func TestGeneratePronounceableLettersOnly(t *testing.T) {
+	i := is.New(t)
+	password, err := Generate(
+		GenerateWithPronounceableOption(),
+		GenerateWithLengthOption(12),
+		GenerateWithoutUpperOption(),
+		GenerateWithoutNumbersOption(),
+		GenerateWithoutSpecialOption(),
+	)
+	i.NoErr(err)
+	i.Equal(len(password), 12)
+	i.Equal(countAny(password, lowerChars), 12)
+}
+
+func TestGeneratePronounceableCounts(t *testing.T) {
+	i := is.New(t)
+	for range 100 {
+		password, err := Generate(
+			GenerateWithPronounceableOption(),
+			GenerateWithLengthOption(10),
+			GenerateWithMinUpperOption(3),
+			GenerateWithMinNumbersOption(2),
+			GenerateWithoutLookAlikesOption(),
+		)
+		i.NoErr(err)
+		i.Equal(len(password), 10)
+		i.True(countAny(password, upperChars) >= 3)
+		i.True(countAny(password, numberChars) >= 2)
+		i.True(!strings.ContainsAny(password, lookAlikeChars))
+	}
+}
+
+func TestGeneratePronounceableTooShort(t *testing.T) {
+	i := is.New(t)
+	_, err := Generate(GenerateWithPronounceableOption(), GenerateWithLengthOption(2),
+		GenerateWithMinNumbersOption(1), GenerateWithMinSpecialOption(1))
+	i.Equal(err, ErrInvalidLength)
+
+	// the default minimums are dropped when they do not fit
+	password, err := Generate(GenerateWithPronounceableOption(), GenerateWithLengthOption(2))
+	i.NoErr(err)
+	i.Equal(len(password), 2)
+}
@@ -0,0 +1,89 @@
+package password
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"math/bits"
+)
+
+// randomBlockSize is the number of bytes read from the source at once
+const randomBlockSize = 512
+
+// randomSource draws unbiased random numbers from an io.Reader. It reads up to
+// a block at once, so a whole password costs a single read instead of one per
+// character, while short readers only need to hold the bytes actually used.
+type randomSource struct {
+	r   io.Reader
+	buf [randomBlockSize]byte
+	pos int
+	end int
+}
+
+// newRandomSource reads from r, or from crypto/rand if r is nil
+func newRandomSource(r io.Reader) *randomSource {
+	if r == nil {
+		r = rand.Reader
+	}
+	return &randomSource{r: r}
+}
+
+func (s *randomSource) uint64() (uint64, error) {
+	if s.pos+8 > s.end {
+		// keep the bytes left over from a short read
+		rest := copy(s.buf[:], s.buf[s.pos:s.end])
+		n, err := io.ReadAtLeast(s.r, s.buf[rest:], 8-rest)
+		if err == io.EOF && rest > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return 0, err
+		}
+		s.pos, s.end = 0, rest+n
+	}
+	v := binary.LittleEndian.Uint64(s.buf[s.pos:])
+	s.pos += 8
+	return v, nil
+}
+
+// index returns a uniformly random integer in [0, n). It uses Lemire's
+// multiply-shift method and rejects the few values that would bias the result.
+func (s *randomSource) index(n int) (int, error) {
+	bound := uint64(n)
+	v, err := s.uint64()
+	if err != nil {
+		return 0, err
+	}
+	hi, lo := bits.Mul64(v, bound)
+	if lo < bound {
+		threshold := -bound % bound
+		for lo < threshold {
+			if v, err = s.uint64(); err != nil {
+				return 0, err
+			}
+			hi, lo = bits.Mul64(v, bound)
+		}
+	}
+	return int(hi), nil
+}
+
+// char returns a uniformly random character of charset
+func (s *randomSource) char(charset []rune) (rune, error) {
+	n, err := s.index(len(charset))
+	if err != nil {
+		return 0, err
+	}
+	return charset[n], nil
+}
+
+// shuffle permutes password uniformly with Fisher–Yates
+func (s *randomSource) shuffle(password []rune) error {
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := s.index(i + 1)
+		if err != nil {
+			return err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+	return nil
+}
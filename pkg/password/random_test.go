@@ -0,0 +1,205 @@
+package password
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+	mrand "math/rand/v2"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func seededReader(seed byte) io.Reader {
+	return mrand.NewChaCha8([32]byte{seed})
+}
+
+func TestRandomSourceIndexRange(t *testing.T) {
+	i := is.New(t)
+	src := newRandomSource(nil)
+	for _, n := range []int{1, 2, 3, 7, 26, 94, 1000, 7776} {
+		for range 1000 {
+			v, err := src.index(n)
+			i.NoErr(err)
+			i.True(v >= 0 && v < n)
+		}
+	}
+}
+
+func TestRandomSourceIndexUniform(t *testing.T) {
+	i := is.New(t)
+	const (
+		n       = 6
+		samples = 60000
+	)
+	src := newRandomSource(seededReader(1))
+	counts := make([]int, n)
+	for range samples {
+		v, err := src.index(n)
+		i.NoErr(err)
+		counts[v]++
+	}
+	var chi2 float64
+	expected := float64(samples) / n
+	for _, c := range counts {
+		d := float64(c) - expected
+		chi2 += d * d / expected
+	}
+	// 99.9th percentile of the chi-squared distribution with 5 degrees of freedom
+	i.True(chi2 < 20.52)
+}
+
+func TestRandomSourceRejectsBiasedValues(t *testing.T) {
+	i := is.New(t)
+	// with n = 3 the zero value lands below the rejection threshold, so the
+	// second value must be used
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 8))
+	buf.Write(bytes.Repeat([]byte{0xff}, randomBlockSize-8))
+	src := newRandomSource(&buf)
+	v, err := src.index(3)
+	i.NoErr(err)
+	i.Equal(v, 2)
+}
+
+func TestRandomSourceReadError(t *testing.T) {
+	i := is.New(t)
+	_, err := newRandomSource(bytes.NewReader(make([]byte, 10))).index(10)
+	i.True(errors.Is(err, io.ErrUnexpectedEOF))
+
+	_, err = Generate(GenerateWithRandOption(bytes.NewReader(nil)))
+	i.True(errors.Is(err, io.EOF))
+}
+
+// chunkReader returns the bytes of r at most size at a time
+type chunkReader struct {
+	r    io.Reader
+	size int
+}
+
+func (c chunkReader) Read(p []byte) (int, error) {
+	return c.r.Read(p[:min(len(p), c.size)])
+}
+
+func TestRandomSourceShortReader(t *testing.T) {
+	i := is.New(t)
+	// 8 characters and the shuffle need far fewer bytes than a block
+	data := make([]byte, 200)
+	_, err := io.ReadFull(seededReader(9), data)
+	i.NoErr(err)
+	password, err := Generate(GenerateWithRandOption(bytes.NewReader(data)), GenerateWithLengthOption(8))
+	i.NoErr(err)
+	i.Equal(len(password), 8)
+
+	// bytes left over from reads that end mid-value are kept, so the result does not depend on read sizes
+	b, err := Generate(GenerateWithRandOption(chunkReader{bytes.NewReader(data), 3}), GenerateWithLengthOption(8))
+	i.NoErr(err)
+	i.Equal(password, b)
+}
+
+func TestGenerateWithRandOptionReproducible(t *testing.T) {
+	for _, mode := range []struct {
+		name    string
+		options []GenerateOption
+	}{
+		{"random", nil},
+		{"pronounceable", []GenerateOption{GenerateWithPronounceableOption()}},
+		{"pattern", []GenerateOption{GenerateWithPatternOption("XXXX-9999-****")}},
+	} {
+		t.Run(mode.name, func(t *testing.T) {
+			is := is.New(t)
+			a, err := Generate(append(mode.options, GenerateWithRandOption(seededReader(7)))...)
+			is.NoErr(err)
+			b, err := Generate(append(mode.options, GenerateWithRandOption(seededReader(7)))...)
+			is.NoErr(err)
+			c, err := Generate(append(mode.options, GenerateWithRandOption(seededReader(8)))...)
+			is.NoErr(err)
+			is.Equal(a, b)
+			is.True(a != c)
+		})
+	}
+}
+
+func TestGenerateBatchWithRandOptionReproducible(t *testing.T) {
+	i := is.New(t)
+	a, err := GenerateBatch(10, GenerateWithRandOption(seededReader(3)))
+	i.NoErr(err)
+	b, err := GenerateBatch(10, GenerateWithRandOption(seededReader(3)))
+	i.NoErr(err)
+	i.Equal(a, b)
+}
+
+func TestGeneratePassphraseWithRandOptionReproducible(t *testing.T) {
+	i := is.New(t)
+	a, err := GeneratePassphrase(PassphraseWithRandOption(seededReader(5)), PassphraseWithNumberOption())
+	i.NoErr(err)
+	b, err := GeneratePassphrase(PassphraseWithRandOption(seededReader(5)), PassphraseWithNumberOption())
+	i.NoErr(err)
+	i.Equal(a, b)
+}
+
+// bigIntIndex is the former per-character implementation, kept as benchmark baseline
+func bigIntIndex(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(i.Int64()), nil
+}
+
+func BenchmarkIndexBigInt(b *testing.B) {
+	for b.Loop() {
+		if _, err := bigIntIndex(94); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkIndexRandomSource(b *testing.B) {
+	src := newRandomSource(nil)
+	for b.Loop() {
+		if _, err := src.index(94); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGenerateBigInt(b *testing.B) {
+	charset := []rune(upperChars + lowerChars + numberChars + specialChars)
+	for b.Loop() {
+		password := make([]rune, 16)
+		for i := range password {
+			n, err := bigIntIndex(len(charset))
+			if err != nil {
+				b.Fatal(err)
+			}
+			password[i] = charset[n]
+		}
+		for i := len(password) - 1; i > 0; i-- {
+			j, err := bigIntIndex(i + 1)
+			if err != nil {
+				b.Fatal(err)
+			}
+			password[i], password[j] = password[j], password[i]
+		}
+		_ = string(password)
+	}
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	for b.Loop() {
+		if _, err := Generate(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGenerateBatch(b *testing.B) {
+	for b.Loop() {
+		if _, err := GenerateBatch(100); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package password
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"dario.lol/gotils/pkg/hash"
+)
+
+// defaultRecoveryCodePattern yields codes like k7dfq-x3m9p, about 50 bits each
+// without look-alike characters
+const defaultRecoveryCodePattern = "aaaaa-aaaaa"
+
+var ErrInvalidRecoveryCodeCount = errors.New("invalid recovery code count: at least one code required")
+
+// RecoveryCodeConfig holds recovery code generation settings
+type RecoveryCodeConfig struct {
+	// Pattern is the code template, see GenerateWithPatternOption
+	Pattern string
+	Params  hash.Argon2idParams
+	// Rand is the source of randomness, crypto/rand if nil
+	Rand io.Reader
+}
+
+// RecoveryCodeOption is a function that modifies RecoveryCodeConfig
+type RecoveryCodeOption func(*RecoveryCodeConfig)
+
+// RecoveryCode is a hashed recovery code that can be redeemed once
+type RecoveryCode struct {
+	Hash string `json:"hash"`
+	Used bool   `json:"used"`
+}
+
+// RecoveryCodes is the stored form of a user's recovery codes. It is not safe
+// for concurrent use, callers should serialize redemptions per user and persist
+// the codes after every successful Redeem.
+type RecoveryCodes []RecoveryCode
+
+// GenerateRecoveryCodes creates count distinct codes to show the user once and
+// their Argon2id hashes to store. Codes are short enough to type, so unlike API
+// keys they are hashed with a slow password hash.
+func GenerateRecoveryCodes(count int, options ...RecoveryCodeOption) ([]string, RecoveryCodes, error) {
+	config := RecoveryCodeConfig{
+		Pattern: defaultRecoveryCodePattern,
+		Params:  hash.Argon2idDefaultParams,
+	}
+	for _, opt := range options {
+		opt(&config)
+	}
+	if count < 1 {
+		return nil, nil, ErrInvalidRecoveryCodeCount
+	}
+
+	codes, err := generateRecoveryCodes(count, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hasher := hash.NewArgon2idHasher(config.Params)
+	stored := make(RecoveryCodes, len(codes))
+	for i, code := range codes {
+		if stored[i].Hash, err = HashPassword(normalizeRecoveryCode(code), hasher); err != nil {
+			return nil, nil, err
+		}
+	}
+	return codes, stored, nil
+}
+
+// generateRecoveryCodes draws count codes that are distinct after
+// normalizeRecoveryCode. Codes are compared the way they are redeemed, so
+// patterns with mixed-case placeholders cannot yield two codes with the same hash.
+func generateRecoveryCodes(count int, config RecoveryCodeConfig) ([]string, error) {
+	generateConfig := newGenerateConfig([]GenerateOption{
+		GenerateWithPatternOption(config.Pattern),
+		GenerateWithoutLookAlikesOption(),
+		GenerateWithRandOption(config.Rand),
+	})
+	src := newRandomSource(generateConfig.Rand)
+	codes := make([]string, 0, count)
+	seen := make(map[string]bool, count)
+	for attempts := 0; len(codes) < count; attempts++ {
+		if attempts/maxBatchAttemptsFactor >= count {
+			return nil, ErrBatchExhausted
+		}
+		code, err := generate(generateConfig, src)
+		if err != nil {
+			return nil, err
+		}
+		if normalized := normalizeRecoveryCode(code); !seen[normalized] {
+			seen[normalized] = true
+			codes = append(codes, code)
+		}
+	}
+	return codes, nil
+}
+
+// Redeem marks the unused code matching code as used and reports whether there
+// was one. Case, spaces and dashes are ignored.
+func (r RecoveryCodes) Redeem(code string) (bool, error) {
+	code = normalizeRecoveryCode(code)
+	hasher := hash.NewArgon2idHasher(hash.Argon2idDefaultParams)
+	for i := range r {
+		if r[i].Used {
+			continue
+		}
+		ok, err := VerifyPassword(r[i].Hash, code, hasher)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			r[i].Used = true
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Remaining returns the number of unused codes
+func (r RecoveryCodes) Remaining() int {
+	var n int
+	for _, c := range r {
+		if !c.Used {
+			n++
+		}
+	}
+	return n
+}
+
+// normalizeRecoveryCode drops separators and case so codes can be typed loosely
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(Normalize(code)))
+}
+
+// RecoveryCodeWithPatternOption sets the code template (default: "aaaaa-aaaaa"), see GenerateWithPatternOption.
+// Codes are redeemed ignoring case, so uppercase and mixed-case placeholders add no entropy over lowercase ones.
+func RecoveryCodeWithPatternOption(pattern string) RecoveryCodeOption {
+	return func(c *RecoveryCodeConfig) {
+		c.Pattern = pattern
+	}
+}
+
+// RecoveryCodeWithParamsOption hashes codes with p (default: hash.Argon2idDefaultParams)
+func RecoveryCodeWithParamsOption(p hash.Argon2idParams) RecoveryCodeOption {
+	return func(c *RecoveryCodeConfig) {
+		c.Params = p
+	}
+}
+
+// RecoveryCodeWithRandOption reads randomness from r instead of crypto/rand
+func RecoveryCodeWithRandOption(r io.Reader) RecoveryCodeOption {
+	return func(c *RecoveryCodeConfig) {
+		c.Rand = r
+	}
+}
@@ -0,0 +1,88 @@
+package password
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func generateTestRecoveryCodes(t *testing.T, count int, options ...RecoveryCodeOption) ([]string, RecoveryCodes) {
+	t.Helper()
+	codes, stored, err := GenerateRecoveryCodes(count, append([]RecoveryCodeOption{RecoveryCodeWithParamsOption(testHistoryParams)}, options...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return codes, stored
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	i := is.New(t)
+	codes, stored := generateTestRecoveryCodes(t, 10)
+	i.Equal(len(codes), 10)
+	i.Equal(len(stored), 10)
+	i.Equal(stored.Remaining(), 10)
+
+	format := regexp.MustCompile(`^[a-z2-9]{5}-[a-z2-9]{5}$`)
+	seen := make(map[string]bool)
+	for n, code := range codes {
+		i.True(format.MatchString(code))
+		i.True(!strings.ContainsAny(code, lookAlikeChars))
+		i.True(!seen[code])
+		seen[code] = true
+		i.True(strings.HasPrefix(stored[n].Hash, "$argon2id$"))
+	}
+}
+
+func TestRecoveryCodesRedeem(t *testing.T) {
+	i := is.New(t)
+	codes, stored := generateTestRecoveryCodes(t, 3)
+
+	ok, err := stored.Redeem(codes[1])
+	i.NoErr(err)
+	i.True(ok)
+	i.True(stored[1].Used)
+	i.Equal(stored.Remaining(), 2)
+
+	// a code can only be used once
+	ok, err = stored.Redeem(codes[1])
+	i.NoErr(err)
+	i.True(!ok)
+
+	// separators and case are ignored
+	ok, err = stored.Redeem(" " + strings.ToUpper(strings.ReplaceAll(codes[0], "-", "")) + " ")
+	i.NoErr(err)
+	i.True(ok)
+	i.Equal(stored.Remaining(), 1)
+
+	ok, err = stored.Redeem("wrong-code0")
+	i.NoErr(err)
+	i.True(!ok)
+}
+
+func TestRecoveryCodesOptions(t *testing.T) {
+	i := is.New(t)
+	codes, _ := generateTestRecoveryCodes(t, 2, RecoveryCodeWithPatternOption("9999-9999"), RecoveryCodeWithRandOption(seededReader(2)))
+	same, _ := generateTestRecoveryCodes(t, 2, RecoveryCodeWithPatternOption("9999-9999"), RecoveryCodeWithRandOption(seededReader(2)))
+	i.Equal(codes, same)
+	i.True(regexp.MustCompile(`^[2-9]{4}-[2-9]{4}$`).MatchString(codes[0]))
+
+	_, _, err := GenerateRecoveryCodes(0)
+	i.True(errors.Is(err, ErrInvalidRecoveryCodeCount))
+}
+
+func TestRecoveryCodesDistinctIgnoringCase(t *testing.T) {
+	i := is.New(t)
+	// a single "*" draws from upper and lower case letters, so codes that only
+	// differ in case would be common among 40 draws
+	codes, stored := generateTestRecoveryCodes(t, 40, RecoveryCodeWithPatternOption("*"))
+	i.Equal(len(stored), 40)
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		normalized := normalizeRecoveryCode(code)
+		i.True(!seen[normalized])
+		seen[normalized] = true
+	}
+}
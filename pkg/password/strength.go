@@ -0,0 +1,924 @@
+package password
+
+import (
+	_ "embed"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+//go:embed data/common_passwords.txt
+var commonPasswordsData string
+
+//go:embed data/common_words.txt
+var commonWordsData string
+
+const (
+	// maxEstimateLength caps the number of runes Estimate looks at, longer
+	// passwords are only rated on their prefix
+	maxEstimateLength = 100
+
+	bruteforceCardinality           = 10
+	minGuessesBeforeGrowingSequence = 10000
+	minSubmatchGuessesSingleChar    = 10
+	minSubmatchGuessesMultiChar     = 50
+	minYearSpace                    = 20
+	maxSequenceDelta                = 5
+)
+
+const (
+	dictionaryPasswords  = "passwords"
+	dictionaryWords      = "words"
+	dictionaryUserInputs = "user_inputs"
+)
+
+// WeaknessKind identifies the pattern a weakness was detected by
+type WeaknessKind string
+
+const (
+	WeaknessDictionary WeaknessKind = "dictionary"
+	WeaknessL33t       WeaknessKind = "l33t"
+	WeaknessKeyboard   WeaknessKind = "keyboard"
+	WeaknessRepeat     WeaknessKind = "repeat"
+	WeaknessSequence   WeaknessKind = "sequence"
+	WeaknessDate       WeaknessKind = "date"
+)
+
+// Weakness is a guessable part of a password. Start and End are rune offsets, End is exclusive.
+type Weakness struct {
+	Kind    WeaknessKind
+	Token   string
+	Start   int
+	End     int
+	Guesses float64
+	Message string
+}
+
+// Strength is the result of Estimate. Score ranges from 0 (too guessable) to 4 (very unguessable).
+type Strength struct {
+	Guesses     float64
+	Entropy     float64
+	Score       int
+	Weaknesses  []Weakness
+	Suggestions []string
+}
+
+type rankedDictionary struct {
+	name   string
+	ranks  map[string]int
+	maxLen int
+}
+
+var defaultDictionaries = []rankedDictionary{
+	newRankedDictionary(dictionaryPasswords, strings.Fields(commonPasswordsData)),
+	newRankedDictionary(dictionaryWords, strings.Fields(commonWordsData)),
+}
+
+func newRankedDictionary(name string, words []string) rankedDictionary {
+	d := rankedDictionary{name: name, ranks: make(map[string]int, len(words))}
+	for _, w := range words {
+		w = strings.ToLower(w)
+		if _, ok := d.ranks[w]; ok || w == "" {
+			continue
+		}
+		d.ranks[w] = len(d.ranks) + 1
+		d.maxLen = max(d.maxLen, len([]rune(w)))
+	}
+	return d
+}
+
+var l33tTable = map[rune][]rune{
+	'4': {'a'}, '@': {'a'}, '8': {'b'}, '(': {'c'}, '{': {'c'}, '[': {'c'}, '<': {'c'},
+	'3': {'e'}, '6': {'g'}, '9': {'g'}, '1': {'i', 'l'}, '!': {'i'}, '|': {'i', 'l'},
+	'7': {'l', 't'}, '0': {'o'}, '$': {'s'}, '5': {'s'}, '+': {'t'}, '%': {'x'}, '2': {'z'},
+}
+
+type keyPosition struct {
+	row, col int
+	shifted  bool
+}
+
+// qwertyRows is a slanted QWERTY layout, each row is offset half a key to the right of the one above
+var qwertyRows = [...][2]string{
+	{"`1234567890-=", "~!@#$%^&*()_+"},
+	{" qwertyuiop[]\\", " QWERTYUIOP{}|"},
+	{" asdfghjkl;'", " ASDFGHJKL:\""},
+	{" zxcvbnm,./", " ZXCVBNM<>?"},
+}
+
+var keyboardDirections = [...][2]int{{0, -1}, {0, 1}, {-1, 0}, {-1, 1}, {1, -1}, {1, 0}}
+
+var (
+	qwertyKeys                               = buildKeyboard()
+	qwertyStartingPositions, qwertyAvgDegree = keyboardStats()
+)
+
+func buildKeyboard() map[rune]keyPosition {
+	keys := make(map[rune]keyPosition)
+	for row, layers := range qwertyRows {
+		for layer, chars := range layers {
+			for col, r := range []rune(chars) {
+				if r != ' ' {
+					keys[r] = keyPosition{row: row, col: col, shifted: layer == 1}
+				}
+			}
+		}
+	}
+	return keys
+}
+
+func keyboardStats() (float64, float64) {
+	occupied := make(map[[2]int]bool)
+	for _, p := range qwertyKeys {
+		occupied[[2]int{p.row, p.col}] = true
+	}
+	var degrees int
+	for pos := range occupied {
+		for _, d := range keyboardDirections {
+			if occupied[[2]int{pos[0] + d[0], pos[1] + d[1]}] {
+				degrees++
+			}
+		}
+	}
+	return float64(len(occupied)), float64(degrees) / float64(len(occupied))
+}
+
+func keyboardDirection(a, b rune) (int, bool) {
+	pa, okA := qwertyKeys[a]
+	pb, okB := qwertyKeys[b]
+	if !okA || !okB {
+		return 0, false
+	}
+	for dir, d := range keyboardDirections {
+		if pb.row == pa.row+d[0] && pb.col == pa.col+d[1] {
+			return dir, true
+		}
+	}
+	return 0, false
+}
+
+// strengthMatch is a candidate pattern covering the runes [i, j) of a password.
+// An empty kind marks a bruteforce segment.
+type strengthMatch struct {
+	kind    WeaknessKind
+	i, j    int
+	token   string
+	guesses float64
+
+	dictionary string
+	rank       int
+	reversed   bool
+	sub        map[rune]rune
+	turns      int
+	shifted    int
+	base       string
+	repeats    int
+	ascending  bool
+	year       int
+	fullDate   bool
+	separator  bool
+}
+
+// Estimate rates how guessable password is by finding the cheapest way to
+// build it from dictionary words, keyboard walks, repeats, sequences, dates
+// and bruteforce. userInputs, such as the user's name or email address, are
+// treated as an additional dictionary.
+func Estimate(password string, userInputs ...string) Strength {
+	pw := []rune(password)
+	if len(pw) > maxEstimateLength {
+		pw = pw[:maxEstimateLength]
+	}
+
+	dicts := defaultDictionaries
+	if inputs := splitUserInputs(userInputs); len(inputs) > 0 {
+		dicts = append(slices.Clone(dicts), newRankedDictionary(dictionaryUserInputs, inputs))
+	}
+
+	guesses, sequence := estimateGuesses(pw, dicts)
+	s := Strength{
+		Guesses: guesses,
+		Entropy: math.Log2(guesses),
+		Score:   guessesToScore(guesses),
+	}
+	for _, m := range sequence {
+		if m.kind == "" {
+			continue
+		}
+		s.Weaknesses = append(s.Weaknesses, Weakness{
+			Kind:    m.kind,
+			Token:   m.token,
+			Start:   m.i,
+			End:     m.j,
+			Guesses: m.guesses,
+			Message: m.warning(len(sequence) == 1),
+		})
+	}
+	s.Suggestions = strengthSuggestions(s.Score, sequence)
+	return s
+}
+
+func splitUserInputs(userInputs []string) []string {
+	var inputs []string
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if input == "" {
+			continue
+		}
+		inputs = append(inputs, input)
+		parts := strings.FieldsFunc(input, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		if len(parts) > 1 {
+			inputs = append(inputs, parts...)
+		}
+	}
+	return inputs
+}
+
+func guessesToScore(guesses float64) int {
+	const delta = 5
+	switch {
+	case guesses < 1e3+delta:
+		return 0
+	case guesses < 1e6+delta:
+		return 1
+	case guesses < 1e8+delta:
+		return 2
+	case guesses < 1e10+delta:
+		return 3
+	default:
+		return 4
+	}
+}
+
+func estimateGuesses(pw []rune, dicts []rankedDictionary) (float64, []strengthMatch) {
+	if len(pw) == 0 {
+		return 1, nil
+	}
+	matches := findMatches(pw, dicts)
+	for k := range matches {
+		matches[k].token = string(pw[matches[k].i:matches[k].j])
+		matches[k].guesses = matchGuesses(matches[k], pw, dicts)
+	}
+	return mostGuessableSequence(pw, matches)
+}
+
+func findMatches(pw []rune, dicts []rankedDictionary) []strengthMatch {
+	lower := make([]rune, len(pw))
+	for k, r := range pw {
+		lower[k] = unicode.ToLower(r)
+	}
+
+	matches := dictionaryMatches(lower, dicts)
+	matches = append(matches, reversedDictionaryMatches(lower, dicts)...)
+	matches = append(matches, l33tMatches(lower, dicts)...)
+	matches = append(matches, keyboardMatches(pw)...)
+	matches = append(matches, repeatMatches(pw)...)
+	matches = append(matches, sequenceMatches(pw)...)
+	matches = append(matches, dateMatches(pw)...)
+	return matches
+}
+
+func dictionaryMatches(lower []rune, dicts []rankedDictionary) []strengthMatch {
+	var matches []strengthMatch
+	for _, d := range dicts {
+		for i := range lower {
+			for j := i + 1; j <= len(lower) && j-i <= d.maxLen; j++ {
+				if rank, ok := d.ranks[string(lower[i:j])]; ok {
+					matches = append(matches, strengthMatch{
+						kind:       WeaknessDictionary,
+						i:          i,
+						j:          j,
+						dictionary: d.name,
+						rank:       rank,
+					})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+func reversedDictionaryMatches(lower []rune, dicts []rankedDictionary) []strengthMatch {
+	n := len(lower)
+	reversed := slices.Clone(lower)
+	slices.Reverse(reversed)
+
+	var matches []strengthMatch
+	for _, m := range dictionaryMatches(reversed, dicts) {
+		m.i, m.j = n-m.j, n-m.i
+		token := lower[m.i:m.j]
+		if len(token) < 2 || isPalindrome(token) {
+			continue
+		}
+		m.reversed = true
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+func isPalindrome(r []rune) bool {
+	for a, b := 0, len(r)-1; a < b; a, b = a+1, b-1 {
+		if r[a] != r[b] {
+			return false
+		}
+	}
+	return true
+}
+
+func l33tMatches(lower []rune, dicts []rankedDictionary) []strengthMatch {
+	var present []rune
+	for _, r := range lower {
+		if _, ok := l33tTable[r]; ok && !slices.Contains(present, r) {
+			present = append(present, r)
+		}
+	}
+	if len(present) == 0 {
+		return nil
+	}
+
+	var matches []strengthMatch
+	seen := make(map[string]bool)
+	for _, sub := range l33tSubstitutions(present) {
+		translated := make([]rune, len(lower))
+		for k, r := range lower {
+			translated[k] = r
+			if letter, ok := sub[r]; ok {
+				translated[k] = letter
+			}
+		}
+		for _, m := range dictionaryMatches(translated, dicts) {
+			used := make(map[rune]rune)
+			for _, r := range lower[m.i:m.j] {
+				if letter, ok := sub[r]; ok {
+					used[r] = letter
+				}
+			}
+			if len(used) == 0 {
+				continue
+			}
+			key := m.dictionary + "\x00" + strconv.Itoa(m.i) + "\x00" + string(translated[m.i:m.j])
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			m.kind = WeaknessL33t
+			m.sub = used
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// l33tSubstitutions returns every way of mapping the present l33t characters to letters
+func l33tSubstitutions(present []rune) []map[rune]rune {
+	subs := []map[rune]rune{{}}
+	for _, r := range present {
+		var next []map[rune]rune
+		for _, sub := range subs {
+			for _, letter := range l33tTable[r] {
+				extended := make(map[rune]rune, len(sub)+1)
+				for k, v := range sub {
+					extended[k] = v
+				}
+				extended[r] = letter
+				next = append(next, extended)
+			}
+		}
+		subs = next
+	}
+	return subs
+}
+
+func keyboardMatches(pw []rune) []strengthMatch {
+	var matches []strengthMatch
+	for i := 0; i < len(pw)-1; {
+		j := i + 1
+		lastDir, turns, shifted := -1, 0, 0
+		if qwertyKeys[pw[i]].shifted {
+			shifted++
+		}
+		for ; j < len(pw); j++ {
+			dir, ok := keyboardDirection(pw[j-1], pw[j])
+			if !ok {
+				break
+			}
+			if dir != lastDir {
+				turns++
+				lastDir = dir
+			}
+			if qwertyKeys[pw[j]].shifted {
+				shifted++
+			}
+		}
+		if j-i > 2 {
+			matches = append(matches, strengthMatch{kind: WeaknessKeyboard, i: i, j: j, turns: turns, shifted: shifted})
+		}
+		i = j
+	}
+	return matches
+}
+
+func repeatMatches(pw []rune) []strengthMatch {
+	var matches []strengthMatch
+	for i := 0; i < len(pw); {
+		bestLen, bestBase := 0, 0
+		for b := 1; i+2*b <= len(pw); b++ {
+			k := 1
+			for i+(k+1)*b <= len(pw) && slices.Equal(pw[i:i+b], pw[i+k*b:i+(k+1)*b]) {
+				k++
+			}
+			if k >= 2 && k*b > bestLen {
+				bestLen, bestBase = k*b, b
+			}
+		}
+		if bestLen == 0 {
+			i++
+			continue
+		}
+		matches = append(matches, strengthMatch{
+			kind:    WeaknessRepeat,
+			i:       i,
+			j:       i + bestLen,
+			base:    string(pw[i : i+bestBase]),
+			repeats: bestLen / bestBase,
+		})
+		i += bestLen
+	}
+	return matches
+}
+
+func sequenceMatches(pw []rune) []strengthMatch {
+	var matches []strengthMatch
+	for i := 0; i < len(pw)-1; {
+		delta := pw[i+1] - pw[i]
+		j := i + 1
+		for j+1 < len(pw) && pw[j+1]-pw[j] == delta {
+			j++
+		}
+		if j-i >= 2 && delta != 0 && abs(int(delta)) <= maxSequenceDelta {
+			matches = append(matches, strengthMatch{kind: WeaknessSequence, i: i, j: j + 1, ascending: delta > 0})
+		}
+		i = j
+	}
+	return matches
+}
+
+func dateMatches(pw []rune) []strengthMatch {
+	var matches []strengthMatch
+	for i := range pw {
+		for j := i + 4; j <= min(i+10, len(pw)); j++ {
+			year, fullDate, separator, ok := parseDate(string(pw[i:j]))
+			if ok {
+				matches = append(matches, strengthMatch{
+					kind:      WeaknessDate,
+					i:         i,
+					j:         j,
+					year:      year,
+					fullDate:  fullDate,
+					separator: separator,
+				})
+			}
+		}
+	}
+
+	// drop dates contained in longer dates, such as the year of 1987-07-13
+	return slices.DeleteFunc(matches, func(m strengthMatch) bool {
+		for _, o := range matches {
+			if o.i <= m.i && o.j >= m.j && o.j-o.i > m.j-m.i {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// parseDate recognizes recent years (1987), digit dates (130787, 1371987) and
+// separated dates (13.07.1987, 1987/7/13)
+func parseDate(s string) (year int, fullDate, separator, ok bool) {
+	if isDigits(s) {
+		if len(s) == 4 && (strings.HasPrefix(s, "19") || strings.HasPrefix(s, "20")) {
+			year, _ = strconv.Atoi(s)
+			return year, false, false, true
+		}
+		if len(s) > 8 {
+			return 0, false, false, false
+		}
+		for a := 1; a < len(s)-1; a++ {
+			for b := a + 1; b < len(s); b++ {
+				if year, ok := dateYear(s[:a], s[a:b], s[b:]); ok {
+					return year, true, false, true
+				}
+			}
+		}
+		return 0, false, false, false
+	}
+
+	sep := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if sep <= 0 || !strings.ContainsRune(" -/\\_.", rune(s[sep])) {
+		return 0, false, false, false
+	}
+	parts := strings.Split(s, s[sep:sep+1])
+	if len(parts) != 3 || !isDigits(parts[0]) || !isDigits(parts[1]) || !isDigits(parts[2]) {
+		return 0, false, false, false
+	}
+	if year, ok := dateYear(parts[0], parts[1], parts[2]); ok {
+		return year, true, true, true
+	}
+	return 0, false, false, false
+}
+
+// dateYear returns the year if the three digit groups form a day, month and
+// year in any common order
+func dateYear(a, b, c string) (int, bool) {
+	if y, ok := parseYear(c); ok && validDayMonth(a, b) {
+		return y, true
+	}
+	if y, ok := parseYear(a); ok && validDayMonth(b, c) {
+		return y, true
+	}
+	return 0, false
+}
+
+func parseYear(s string) (int, bool) {
+	y, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	switch len(s) {
+	case 2:
+		if y > 50 {
+			return 1900 + y, true
+		}
+		return 2000 + y, true
+	case 4:
+		return y, y >= 1000 && y <= 2050
+	default:
+		return 0, false
+	}
+}
+
+func validDayMonth(a, b string) bool {
+	if len(a) > 2 || len(b) > 2 {
+		return false
+	}
+	x, errX := strconv.Atoi(a)
+	y, errY := strconv.Atoi(b)
+	if errX != nil || errY != nil {
+		return false
+	}
+	return (x >= 1 && x <= 31 && y >= 1 && y <= 12) || (y >= 1 && y <= 31 && x >= 1 && x <= 12)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func matchGuesses(m strengthMatch, pw []rune, dicts []rankedDictionary) float64 {
+	token := pw[m.i:m.j]
+	var guesses float64
+	switch m.kind {
+	case WeaknessDictionary, WeaknessL33t:
+		guesses = float64(m.rank) * uppercaseVariations(token) * l33tVariations(m, token)
+		if m.reversed {
+			guesses *= 2
+		}
+	case WeaknessKeyboard:
+		guesses = keyboardGuesses(m)
+	case WeaknessRepeat:
+		baseGuesses, _ := estimateGuesses([]rune(m.base), dicts)
+		guesses = baseGuesses * float64(m.repeats)
+	case WeaknessSequence:
+		guesses = sequenceGuesses(m, token)
+	case WeaknessDate:
+		guesses = max(math.Abs(float64(m.year-time.Now().Year())), minYearSpace)
+		if m.fullDate {
+			guesses *= 365
+		}
+		if m.separator {
+			guesses *= 4
+		}
+	}
+
+	if len(token) < len(pw) {
+		minGuesses := float64(minSubmatchGuessesMultiChar)
+		if len(token) == 1 {
+			minGuesses = minSubmatchGuessesSingleChar
+		}
+		guesses = max(guesses, minGuesses)
+	}
+	return guesses
+}
+
+func uppercaseVariations(token []rune) float64 {
+	var upper, lower int
+	for _, r := range token {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+	if lower == 0 || (upper == 1 && (unicode.IsUpper(token[0]) || unicode.IsUpper(token[len(token)-1]))) {
+		return 2
+	}
+	var variations float64
+	for k := 1; k <= min(upper, lower); k++ {
+		variations += binomial(upper+lower, k)
+	}
+	return variations
+}
+
+func l33tVariations(m strengthMatch, token []rune) float64 {
+	variations := 1.0
+	for subbed, letter := range m.sub {
+		var s, u int
+		for _, r := range token {
+			switch unicode.ToLower(r) {
+			case subbed:
+				s++
+			case letter:
+				u++
+			}
+		}
+		if s == 0 || u == 0 {
+			variations *= 2
+			continue
+		}
+		var possibilities float64
+		for k := 1; k <= min(s, u); k++ {
+			possibilities += binomial(s+u, k)
+		}
+		variations *= possibilities
+	}
+	return variations
+}
+
+func keyboardGuesses(m strengthMatch) float64 {
+	length := m.j - m.i
+	var guesses float64
+	for l := 2; l <= length; l++ {
+		for t := 1; t <= min(m.turns, l-1); t++ {
+			guesses += binomial(l-1, t-1) * qwertyStartingPositions * math.Pow(qwertyAvgDegree, float64(t))
+		}
+	}
+	if m.shifted > 0 {
+		unshifted := length - m.shifted
+		if unshifted == 0 {
+			guesses *= 2
+		} else {
+			var variations float64
+			for k := 1; k <= min(m.shifted, unshifted); k++ {
+				variations += binomial(length, k)
+			}
+			guesses *= variations
+		}
+	}
+	return guesses
+}
+
+func sequenceGuesses(m strengthMatch, token []rune) float64 {
+	var base float64
+	switch first := token[0]; {
+	case strings.ContainsRune("aAzZ019", first):
+		base = 4
+	case unicode.IsDigit(first):
+		base = 10
+	default:
+		base = 26
+	}
+	if !m.ascending {
+		base *= 2
+	}
+	return base * float64(len(token))
+}
+
+func binomial(n, k int) float64 {
+	if k > n {
+		return 0
+	}
+	r := 1.0
+	for d := 1; d <= k; d++ {
+		r = r * float64(n-k+d) / float64(d)
+	}
+	return r
+}
+
+func factorial(n int) float64 {
+	r := 1.0
+	for k := 2; k <= n; k++ {
+		r *= float64(k)
+	}
+	return r
+}
+
+func bruteforceMatch(pw []rune, i, j int) strengthMatch {
+	length := j - i
+	guesses := math.Pow(bruteforceCardinality, float64(length))
+	minGuesses := float64(minSubmatchGuessesSingleChar + 1)
+	if length > 1 {
+		minGuesses = minSubmatchGuessesMultiChar + 1
+	}
+	return strengthMatch{
+		i:       i,
+		j:       j,
+		token:   string(pw[i:j]),
+		guesses: max(guesses, minGuesses),
+	}
+}
+
+// mostGuessableSequence finds the sequence of non-overlapping matches covering
+// pw that minimizes l! * product(guesses) + D^(l-1), where l is the sequence length
+func mostGuessableSequence(pw []rune, matches []strengthMatch) (float64, []strengthMatch) {
+	type step struct {
+		valid bool
+		match strengthMatch
+		pi    float64
+		g     float64
+	}
+	n := len(pw)
+	byEnd := make([][]strengthMatch, n)
+	for _, m := range matches {
+		byEnd[m.j-1] = append(byEnd[m.j-1], m)
+	}
+	optimal := make([][]step, n)
+	for k := range optimal {
+		optimal[k] = make([]step, n+1)
+	}
+
+	update := func(m strengthMatch, l int) {
+		k := m.j - 1
+		pi := m.guesses
+		if l > 1 {
+			pi *= optimal[m.i-1][l-1].pi
+		}
+		g := factorial(l)*pi + math.Pow(minGuessesBeforeGrowingSequence, float64(l-1))
+		for cl := 1; cl <= l; cl++ {
+			if optimal[k][cl].valid && optimal[k][cl].g <= g {
+				return
+			}
+		}
+		optimal[k][l] = step{valid: true, match: m, pi: pi, g: g}
+	}
+
+	for k := range n {
+		for _, m := range byEnd[k] {
+			if m.i == 0 {
+				update(m, 1)
+				continue
+			}
+			for l, s := range optimal[m.i-1] {
+				if s.valid {
+					update(m, l+1)
+				}
+			}
+		}
+
+		update(bruteforceMatch(pw, 0, k+1), 1)
+		for i := 1; i <= k; i++ {
+			for l, s := range optimal[i-1] {
+				// consecutive bruteforce runs are covered by a single longer run
+				if s.valid && s.match.kind != "" {
+					update(bruteforceMatch(pw, i, k+1), l+1)
+				}
+			}
+		}
+	}
+
+	bestL, bestG := 0, math.Inf(1)
+	for l, s := range optimal[n-1] {
+		if s.valid && s.g < bestG {
+			bestL, bestG = l, s.g
+		}
+	}
+	var sequence []strengthMatch
+	for k, l := n-1, bestL; k >= 0; l-- {
+		m := optimal[k][l].match
+		sequence = append(sequence, m)
+		k = m.i - 1
+	}
+	slices.Reverse(sequence)
+	return bestG, sequence
+}
+
+func (m strengthMatch) warning(sole bool) string {
+	switch m.kind {
+	case WeaknessDictionary, WeaknessL33t:
+		switch m.dictionary {
+		case dictionaryPasswords:
+			switch {
+			case m.kind == WeaknessL33t || m.reversed:
+				return "This is similar to a commonly used password"
+			case m.rank <= 10:
+				return "This is a top-10 common password"
+			case m.rank <= 100:
+				return "This is a top-100 common password"
+			default:
+				return "This is a very common password"
+			}
+		case dictionaryUserInputs:
+			return "Avoid personal information such as names or email addresses"
+		default:
+			if sole {
+				return "A word by itself is easy to guess"
+			}
+			return "Common words and names are easy to guess"
+		}
+	case WeaknessKeyboard:
+		if m.turns == 1 {
+			return "Straight rows of keys are easy to guess"
+		}
+		return "Short keyboard patterns are easy to guess"
+	case WeaknessRepeat:
+		if len([]rune(m.base)) == 1 {
+			return `Repeats like "aaa" are easy to guess`
+		}
+		return `Repeats like "abcabcabc" are only slightly harder to guess than "abc"`
+	case WeaknessSequence:
+		return "Sequences like abc or 6543 are easy to guess"
+	case WeaknessDate:
+		return "Dates are often easy to guess"
+	}
+	return ""
+}
+
+func (m strengthMatch) suggestions() []string {
+	switch m.kind {
+	case WeaknessDictionary, WeaknessL33t:
+		var suggestions []string
+		token := []rune(m.token)
+		var upper, lower int
+		for _, r := range token {
+			switch {
+			case unicode.IsUpper(r):
+				upper++
+			case unicode.IsLower(r):
+				lower++
+			}
+		}
+		if len(token) > 1 && upper > 0 && lower == 0 {
+			suggestions = append(suggestions, "All-uppercase is almost as easy to guess as all-lowercase")
+		} else if upper == 1 && unicode.IsUpper(token[0]) {
+			suggestions = append(suggestions, "Capitalization doesn't help very much")
+		}
+		if m.reversed && len(token) >= 4 {
+			suggestions = append(suggestions, "Reversed words aren't much harder to guess")
+		}
+		if m.kind == WeaknessL33t {
+			suggestions = append(suggestions, "Predictable substitutions like '@' instead of 'a' don't help very much")
+		}
+		return suggestions
+	case WeaknessKeyboard:
+		return []string{"Use a longer keyboard pattern with more turns"}
+	case WeaknessRepeat:
+		return []string{"Avoid repeated words and characters"}
+	case WeaknessSequence:
+		return []string{"Avoid sequences"}
+	case WeaknessDate:
+		return []string{"Avoid dates and years that are associated with you"}
+	}
+	return nil
+}
+
+func strengthSuggestions(score int, sequence []strengthMatch) []string {
+	if len(sequence) == 0 {
+		return []string{
+			"Use a few words, avoid common phrases",
+			"No need for symbols, digits, or uppercase letters",
+		}
+	}
+	if score > 2 {
+		return nil
+	}
+	suggestions := []string{"Add another word or two. Uncommon words are better."}
+	for _, m := range sequence {
+		for _, s := range m.suggestions() {
+			if !slices.Contains(suggestions, s) {
+				suggestions = append(suggestions, s)
+			}
+		}
+	}
+	return suggestions
+}
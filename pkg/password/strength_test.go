@@ -0,0 +1,114 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestEstimateWeaknesses(t *testing.T) {
+	tests := []struct {
+		password string
+		kind     WeaknessKind
+		token    string
+		maxScore int
+	}{
+		{"password", WeaknessDictionary, "password", 0},
+		{"Password1!", WeaknessDictionary, "Password1", 1},
+		{"drowssap", WeaknessDictionary, "drowssap", 0},
+		{"dr4g0n", WeaknessL33t, "dr4g0n", 0},
+		{"P@ssw0rd", WeaknessL33t, "P@ssw0rd", 0},
+		{"zxcfrt", WeaknessKeyboard, "zxcfrt", 1},
+		{"aaaaaaaa", WeaknessRepeat, "aaaaaaaa", 0},
+		{"abcabcabc", WeaknessRepeat, "abcabcabc", 0},
+		{"abcdefgh", WeaknessSequence, "abcdefgh", 0},
+		{"97531", WeaknessSequence, "97531", 0},
+		{"13/07/1987", WeaknessDate, "13/07/1987", 1},
+		{"130787", WeaknessDate, "130787", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			is := is.New(t)
+			s := Estimate(tt.password)
+			is.True(s.Score <= tt.maxScore)
+			is.Equal(len(s.Weaknesses), 1)
+			is.Equal(s.Weaknesses[0].Kind, tt.kind)
+			is.Equal(s.Weaknesses[0].Token, tt.token)
+			is.True(s.Weaknesses[0].Message != "")
+			is.True(len(s.Suggestions) > 0)
+		})
+	}
+}
+
+func TestEstimateEmpty(t *testing.T) {
+	i := is.New(t)
+	s := Estimate("")
+	i.Equal(s.Score, 0)
+	i.Equal(s.Guesses, 1.0)
+	i.Equal(s.Entropy, 0.0)
+	i.Equal(len(s.Weaknesses), 0)
+	i.Equal(len(s.Suggestions), 2)
+}
+
+func TestEstimateStrong(t *testing.T) {
+	i := is.New(t)
+	s := Estimate("Xk9#mQ2$vL7!pR4")
+	i.Equal(s.Score, 4)
+	i.True(s.Entropy > 40)
+	i.Equal(len(s.Suggestions), 0)
+}
+
+func TestEstimateScoreIncreasesWithLength(t *testing.T) {
+	i := is.New(t)
+	short := Estimate("tiger")
+	long := Estimate("tiger purple kitchen window")
+	i.True(long.Guesses > short.Guesses)
+	i.True(long.Score > short.Score)
+}
+
+func TestEstimateSuggestions(t *testing.T) {
+	i := is.New(t)
+	s := Estimate("P@ssw0rd")
+	i.True(strings.Contains(strings.Join(s.Suggestions, "\n"), "Capitalization"))
+	i.True(strings.Contains(strings.Join(s.Suggestions, "\n"), "substitutions"))
+}
+
+func TestEstimateUserInputs(t *testing.T) {
+	i := is.New(t)
+	without := Estimate("zorbulon1")
+	with := Estimate("zorbulon1", "zorbulon@example.com")
+	i.True(with.Guesses < without.Guesses)
+	i.Equal(with.Weaknesses[0].Kind, WeaknessDictionary)
+	i.Equal(with.Weaknesses[0].Token, "zorbulon")
+}
+
+func TestEstimateOffsetsAreRunes(t *testing.T) {
+	i := is.New(t)
+	s := Estimate("ééépassword")
+	var found bool
+	for _, w := range s.Weaknesses {
+		if w.Token == "password" {
+			found = true
+			i.Equal(w.Start, 3)
+			i.Equal(w.End, 11)
+		}
+	}
+	i.True(found)
+}
+
+func TestEstimateLongPassword(t *testing.T) {
+	i := is.New(t)
+	s := Estimate(strings.Repeat("a1B!", 200))
+	i.True(s.Guesses > 0)
+}
+
+func TestVerifyWithMinScore(t *testing.T) {
+	i := is.New(t)
+	err := Verify("Password1!", VerifyWithMinScoreOption(3))
+	i.True(err != nil)
+	i.True(strings.Contains(err.Error(), "too guessable"))
+
+	err = Verify("Xk9#mQ2$vL7!pR4", VerifyWithMinScoreOption(3))
+	i.NoErr(err)
+}
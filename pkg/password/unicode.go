@@ -0,0 +1,92 @@
+package password
+
+import (
+	"unicode"
+
+	"dario.lol/gotils/pkg/hash"
+	"golang.org/x/text/unicode/norm"
+)
+
+const zeroWidthJoiner = '\u200d'
+
+// Normalize returns the NFKC form of password, as recommended by NIST SP 800-63B.
+// Apply it before hashing so that visually identical input, such as a composed
+// and a decomposed "é" or full-width letters, always yields the same hash.
+// Verify, HashPassword and VerifyPassword normalize on their own.
+func Normalize(password string) string {
+	return norm.NFKC.String(password)
+}
+
+// HashPassword normalizes password and hashes it with h, or with the preferred
+// hasher of hash.DefaultRegistry if h is not given
+func HashPassword(password string, h ...hash.Hasher) (string, error) {
+	password = Normalize(password)
+	if len(h) > 0 {
+		return h[0].Hash([]byte(password))
+	}
+	return hash.HashString(password)
+}
+
+// VerifyPassword normalizes password and checks it against hashedPassword with
+// h, or with hash.DefaultRegistry if h is not given
+func VerifyPassword(hashedPassword, password string, h ...hash.Hasher) (bool, error) {
+	password = Normalize(password)
+	if len(h) > 0 {
+		return h[0].Verify(hashedPassword, []byte(password))
+	}
+	return hash.VerifyString(hashedPassword, password)
+}
+
+// Length approximates the number of user-perceived characters of password.
+// Combining marks, emoji modifiers, zero-width joiner sequences and flag pairs
+// count as a single character together with the character they attach to. It
+// is a simplification of Unicode extended grapheme clusters (UAX #29), not a
+// full implementation, and may differ for rarer scripts.
+func Length(password string) int {
+	var (
+		length   int
+		prev     rune = -1
+		regional int
+	)
+	for _, r := range password {
+		switch {
+		case prev == -1:
+			length++
+		case prev == '\r' && r == '\n':
+		case isGraphemeExtend(r) || prev == zeroWidthJoiner:
+		case isRegionalIndicator(r) && regional%2 == 1:
+		default:
+			length++
+		}
+		if isRegionalIndicator(r) {
+			regional++
+		} else {
+			regional = 0
+		}
+		prev = r
+	}
+	return length
+}
+
+// isGraphemeExtend reports whether r attaches to the preceding character
+func isGraphemeExtend(r rune) bool {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc):
+		return true
+	case r == zeroWidthJoiner:
+		return true
+	case r >= 0xfe00 && r <= 0xfe0f, r >= 0xe0100 && r <= 0xe01ef: // variation selectors
+		return true
+	case r >= 0x1f3fb && r <= 0x1f3ff: // emoji skin tone modifiers
+		return true
+	case r >= 0xe0020 && r <= 0xe007f: // emoji tag sequences
+		return true
+	case r >= 0x1160 && r <= 0x11ff: // hangul medial vowels and final consonants
+		return true
+	}
+	return false
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
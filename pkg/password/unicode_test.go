@@ -0,0 +1,161 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"dario.lol/gotils/pkg/hash"
+	"github.com/matryer/is"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     string
+	}{
+		{"ascii", "Test123!", "Test123!"},
+		{"decomposed", "cafe\u0301", "caf\u00e9"},
+		{"full width", "ｆｕｌｌ", "full"},
+		{"ligature", "ﬁne", "fine"},
+		{"superscript", "x²", "x2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(Normalize(tt.password), tt.want)
+		})
+	}
+}
+
+func TestLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     int
+	}{
+		{"empty", "", 0},
+		{"ascii", "password", 8},
+		{"umlauts", "äöü€", 4},
+		{"combining mark", "cafe\u0301", 4},
+		{"flags", "\U0001f1e9\U0001f1ea\U0001f1eb\U0001f1f7", 2},
+		{"odd regional indicator", "\U0001f1e9\U0001f1ea\U0001f1eb", 2},
+		{"skin tone", "\U0001f44d\U0001f3fd", 1},
+		{"family", "\U0001f468\u200d\U0001f469\u200d\U0001f467", 1},
+		{"variation selector", "\u2764\ufe0fa", 2},
+		{"crlf", "a\r\nb", 3},
+		{"hangul jamo", "\u1100\u1161\u11a8", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(Length(tt.password), tt.want)
+		})
+	}
+}
+
+func TestGenerateMultiByteCharset(t *testing.T) {
+	i := is.New(t)
+	const charset = "äöü€"
+	for range 100 {
+		password, err := Generate(GenerateWithCustomCharsetOption(charset), GenerateWithLengthOption(12))
+		i.NoErr(err)
+		i.True(utf8.ValidString(password))
+		i.Equal(utf8.RuneCountInString(password), 12)
+		for _, r := range password {
+			i.True(strings.ContainsRune(charset, r))
+		}
+	}
+}
+
+func TestGenerateMultiByteCharsetClasses(t *testing.T) {
+	i := is.New(t)
+	for range 100 {
+		password, err := Generate(
+			GenerateWithCustomCharsetOption("ÄÖÜäöü٣€"),
+			GenerateWithLengthOption(8),
+			GenerateWithMinUpperOption(2),
+			GenerateWithMinNumbersOption(2),
+		)
+		i.NoErr(err)
+		i.True(utf8.ValidString(password))
+		i.True(countAny(password, "ÄÖÜ") >= 2)
+		i.True(countAny(password, "٣") >= 2)
+		i.True(countAny(password, "äöü") >= 1)
+		i.True(countAny(password, "€") >= 1)
+	}
+}
+
+func TestGenerateMultiByteExcludedChars(t *testing.T) {
+	i := is.New(t)
+	for range 50 {
+		password, err := Generate(GenerateWithCustomCharsetOption("äöü€"), GenerateWithExcludedCharsOption("ö€"))
+		i.NoErr(err)
+		i.True(!strings.ContainsAny(password, "ö€"))
+	}
+}
+
+func TestGeneratePatternMultiByteLiteral(t *testing.T) {
+	i := is.New(t)
+	password, err := Generate(GenerateWithPatternOption("€-999"))
+	i.NoErr(err)
+	i.True(utf8.ValidString(password))
+	i.True(strings.HasPrefix(password, "€-"))
+	i.Equal(utf8.RuneCountInString(password), 5)
+}
+
+func TestVerifyCountsCharacters(t *testing.T) {
+	i := is.New(t)
+	// 8 characters but 13 bytes
+	i.NoErr(Verify("Äöü€ab1!"))
+
+	err := Verify("Äöü€ab1!", VerifyWithMaxLengthOption(8))
+	i.NoErr(err)
+
+	// x with a combining acute accent has no composed form and stays one character
+	err = Verify("Ab1!x\u0301", VerifyWithMinLengthOption(6))
+	var pe *PolicyError
+	i.True(errors.As(err, &pe))
+	i.Equal(pe.Violations[0], Violation{Code: CodeTooShort, Threshold: 6, Actual: 5})
+}
+
+func TestVerifyNormalizes(t *testing.T) {
+	i := is.New(t)
+	// full-width letters normalize to ASCII and match the forbidden substring
+	err := Verify("Ｄａｒｉｏ123!", VerifyWithForbiddenSubstringsOption("dario"))
+	i.True(errors.Is(err, ErrForbiddenSubstring))
+
+	err = Verify("Hello123!", VerifyWithForbiddenSubstringsOption("ｈｅｌｌｏ"))
+	i.True(errors.Is(err, ErrForbiddenSubstring))
+
+	// composed and decomposed input hit the same blocklist entry
+	err = Verify("Cafe\u0301123!", VerifyWithBlocklistOption(NewPasswordSet("caf\u00e9123!")))
+	i.True(errors.Is(err, ErrBreachedPassword))
+}
+
+func TestHashPasswordNormalizes(t *testing.T) {
+	i := is.New(t)
+	hasher := hash.NewArgon2idHasher(testHistoryParams)
+	hashed, err := HashPassword("caf\u00e9-Password", hasher)
+	i.NoErr(err)
+	i.True(strings.HasPrefix(hashed, "$argon2id$v=19$m=64,t=1,p=1$"))
+
+	ok, err := VerifyPassword(hashed, "cafe\u0301-Password", hasher)
+	i.NoErr(err)
+	i.True(ok)
+	ok, err = VerifyPassword(hashed, "cafe\u0301-Password")
+	i.NoErr(err)
+	i.True(ok)
+	ok, err = VerifyPassword(hashed, "cafe-Password", hasher)
+	i.NoErr(err)
+	i.True(!ok)
+
+	// full-width letters hash like their ASCII form
+	hashed, err = HashPassword("\uff30\uff41\uff53\uff53")
+	i.NoErr(err)
+	ok, err = VerifyPassword(hashed, "Pass")
+	i.NoErr(err)
+	i.True(ok)
+}
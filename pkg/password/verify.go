@@ -14,12 +14,25 @@ type VerifyConfig struct {
 	RequireLowercase bool
 	RequireNumbers   bool
 	RequireSpecial   bool
+	// MinUppercase, MinLowercase, MinNumbers and MinSpecial raise the required
+	// count of a class above one
+	MinUppercase        int
+	MinLowercase        int
+	MinNumbers          int
+	MinSpecial          int
+	MaxRepeats          int
+	ForbiddenSubstrings []string
+	MinScore            int
+	Blocklists          []Blocklist
+	History             PasswordHistory
 }
 
 // VerifyOption is a function that modifies VerifyConfig
 type VerifyOption func(*VerifyConfig)
 
-// Verify Validate password against common criteria
+// Verify Validate password against common criteria. All violated rules are
+// reported together in a *PolicyError. The password is normalized with
+// Normalize first and its length is counted in characters, see Length.
 func Verify(password string, options ...VerifyOption) error {
 	// Default configuration
 	config := VerifyConfig{
@@ -35,54 +48,118 @@ func Verify(password string, options ...VerifyOption) error {
 		opt(&config)
 	}
 
-	if len(password) < config.MinLength {
-		return fmt.Errorf("password must be at least %d characters long", config.MinLength)
-	}
-	if len(password) > config.MaxLength {
-		return fmt.Errorf("password must not exceed %d characters", config.MaxLength)
-	}
+	password = Normalize(password)
 
-	var (
-		hasUpper   bool
-		hasLower   bool
-		hasNumber  bool
-		hasSpecial bool
-	)
+	var violations []Violation
+	if length := Length(password); length < config.MinLength {
+		violations = append(violations, Violation{Code: CodeTooShort, Threshold: config.MinLength, Actual: length})
+	} else if config.MaxLength > 0 && length > config.MaxLength {
+		violations = append(violations, Violation{Code: CodeTooLong, Threshold: config.MaxLength, Actual: length})
+	}
 
+	var upper, lower, numbers, special int
 	for _, char := range password {
 		switch {
 		case unicode.IsUpper(char):
-			hasUpper = true
+			upper++
 		case unicode.IsLower(char):
-			hasLower = true
+			lower++
 		case unicode.IsNumber(char):
-			hasNumber = true
+			numbers++
 		case unicode.IsPunct(char) || unicode.IsSymbol(char):
-			hasSpecial = true
+			special++
 		}
 	}
 
-	var violations []string
-	if config.RequireUppercase && !hasUpper {
-		violations = append(violations, "uppercase letter")
+	classes := []struct {
+		required bool
+		min      int
+		code     ViolationCode
+		count    int
+	}{
+		{config.RequireUppercase, config.MinUppercase, CodeMissingUppercase, upper},
+		{config.RequireLowercase, config.MinLowercase, CodeMissingLowercase, lower},
+		{config.RequireNumbers, config.MinNumbers, CodeMissingNumber, numbers},
+		{config.RequireSpecial, config.MinSpecial, CodeMissingSpecial, special},
 	}
-	if config.RequireLowercase && !hasLower {
-		violations = append(violations, "lowercase letter")
+	for _, class := range classes {
+		required := class.min
+		if class.required {
+			required = max(required, 1)
+		}
+		if class.count < required {
+			violations = append(violations, Violation{Code: class.code, Threshold: required, Actual: class.count})
+		}
 	}
-	if config.RequireNumbers && !hasNumber {
-		violations = append(violations, "number")
+
+	if config.MaxRepeats > 0 {
+		if longest := longestRun(password); longest > config.MaxRepeats {
+			violations = append(violations, Violation{Code: CodeTooManyRepeats, Threshold: config.MaxRepeats, Actual: longest})
+		}
 	}
-	if config.RequireSpecial && !hasSpecial {
-		violations = append(violations, "special character")
+
+	var forbidden int
+	lowered := strings.ToLower(password)
+	for _, sub := range config.ForbiddenSubstrings {
+		if sub != "" && strings.Contains(lowered, strings.ToLower(Normalize(sub))) {
+			forbidden++
+		}
+	}
+	if forbidden > 0 {
+		violations = append(violations, Violation{Code: CodeForbiddenSubstring, Actual: forbidden})
 	}
 
-	if len(violations) > 0 {
-		return fmt.Errorf("password must contain at least one %s", strings.Join(violations, ", "))
+	for _, l := range config.Blocklists {
+		found, err := l.Contains(password)
+		if err != nil {
+			return fmt.Errorf("failed to check password blocklist: %w", err)
+		}
+		if found {
+			violations = append(violations, Violation{Code: CodeBreached})
+			break
+		}
+	}
+
+	if config.History != nil {
+		used, err := config.History.Used(password)
+		if err != nil {
+			return fmt.Errorf("failed to check password history: %w", err)
+		}
+		if used {
+			violations = append(violations, Violation{Code: CodeReused})
+		}
+	}
+
+	if config.MinScore > 0 {
+		if strength := Estimate(password); strength.Score < config.MinScore {
+			violations = append(violations, Violation{Code: CodeTooGuessable, Threshold: config.MinScore, Actual: strength.Score})
+		}
 	}
 
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
 	return nil
 }
 
+// longestRun returns the length of the longest run of identical characters
+func longestRun(password string) int {
+	var (
+		longest, run int
+		last         rune = -1
+	)
+	for _, r := range password {
+		if r == last {
+			run++
+		} else {
+			run = 1
+			last = r
+		}
+		longest = max(longest, run)
+	}
+	return longest
+}
+
 // VerifyWithMinLengthOption sets minimum password length
 func VerifyWithMinLengthOption(length int) VerifyOption {
 	return func(c *VerifyConfig) {
@@ -90,7 +167,7 @@ func VerifyWithMinLengthOption(length int) VerifyOption {
 	}
 }
 
-// VerifyWithMaxLengthOption sets maximum password length
+// VerifyWithMaxLengthOption sets maximum password length, zero disables the limit
 func VerifyWithMaxLengthOption(length int) VerifyOption {
 	return func(c *VerifyConfig) {
 		c.MaxLength = length
@@ -124,3 +201,69 @@ func VerifyWithoutSpecialOption() VerifyOption {
 		c.RequireSpecial = false
 	}
 }
+
+// VerifyWithMinScoreOption requires an Estimate score of at least score (0-4)
+func VerifyWithMinScoreOption(score int) VerifyOption {
+	return func(c *VerifyConfig) {
+		c.MinScore = score
+	}
+}
+
+// VerifyWithBlocklistOption rejects passwords found in any of lists, or in CommonPasswords if none are given
+func VerifyWithBlocklistOption(lists ...Blocklist) VerifyOption {
+	return func(c *VerifyConfig) {
+		if len(lists) == 0 {
+			lists = []Blocklist{CommonPasswords}
+		}
+		c.Blocklists = append(c.Blocklists, lists...)
+	}
+}
+
+// VerifyWithMinUppercaseOption requires at least count uppercase letters
+func VerifyWithMinUppercaseOption(count int) VerifyOption {
+	return func(c *VerifyConfig) {
+		c.MinUppercase = count
+	}
+}
+
+// VerifyWithMinLowercaseOption requires at least count lowercase letters
+func VerifyWithMinLowercaseOption(count int) VerifyOption {
+	return func(c *VerifyConfig) {
+		c.MinLowercase = count
+	}
+}
+
+// VerifyWithMinNumbersOption requires at least count numbers
+func VerifyWithMinNumbersOption(count int) VerifyOption {
+	return func(c *VerifyConfig) {
+		c.MinNumbers = count
+	}
+}
+
+// VerifyWithMinSpecialOption requires at least count special characters
+func VerifyWithMinSpecialOption(count int) VerifyOption {
+	return func(c *VerifyConfig) {
+		c.MinSpecial = count
+	}
+}
+
+// VerifyWithMaxRepeatsOption rejects more than count consecutive identical characters
+func VerifyWithMaxRepeatsOption(count int) VerifyOption {
+	return func(c *VerifyConfig) {
+		c.MaxRepeats = count
+	}
+}
+
+// VerifyWithForbiddenSubstringsOption rejects passwords containing any of substrings, ignoring case
+func VerifyWithForbiddenSubstringsOption(substrings ...string) VerifyOption {
+	return func(c *VerifyConfig) {
+		c.ForbiddenSubstrings = append(c.ForbiddenSubstrings, substrings...)
+	}
+}
+
+// VerifyWithHistoryOption rejects passwords that history reports as used before
+func VerifyWithHistoryOption(history PasswordHistory) VerifyOption {
+	return func(c *VerifyConfig) {
+		c.History = history
+	}
+}
@@ -0,0 +1,158 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ViolationCode is a machine-readable identifier of a violated password rule
+type ViolationCode string
+
+const (
+	CodeTooShort           ViolationCode = "too_short"
+	CodeTooLong            ViolationCode = "too_long"
+	CodeMissingUppercase   ViolationCode = "missing_uppercase"
+	CodeMissingLowercase   ViolationCode = "missing_lowercase"
+	CodeMissingNumber      ViolationCode = "missing_number"
+	CodeMissingSpecial     ViolationCode = "missing_special"
+	CodeTooManyRepeats     ViolationCode = "too_many_repeats"
+	CodeForbiddenSubstring ViolationCode = "forbidden_substring"
+	CodeTooGuessable       ViolationCode = "too_guessable"
+	CodeBreached           ViolationCode = "breached"
+	CodeReused             ViolationCode = "reused"
+)
+
+var (
+	ErrTooShort           = errors.New("password is too short")
+	ErrTooLong            = errors.New("password is too long")
+	ErrMissingUppercase   = errors.New("password is missing an uppercase letter")
+	ErrMissingLowercase   = errors.New("password is missing a lowercase letter")
+	ErrMissingNumber      = errors.New("password is missing a number")
+	ErrMissingSpecial     = errors.New("password is missing a special character")
+	ErrTooManyRepeats     = errors.New("password repeats a character too often")
+	ErrForbiddenSubstring = errors.New("password contains a forbidden word")
+	ErrTooGuessable       = errors.New("password is too guessable")
+	ErrBreachedPassword   = errors.New("password is too common or has appeared in a data breach")
+	ErrReusedPassword     = errors.New("password was used before")
+)
+
+var violationSentinels = map[ViolationCode]error{
+	CodeTooShort:           ErrTooShort,
+	CodeTooLong:            ErrTooLong,
+	CodeMissingUppercase:   ErrMissingUppercase,
+	CodeMissingLowercase:   ErrMissingLowercase,
+	CodeMissingNumber:      ErrMissingNumber,
+	CodeMissingSpecial:     ErrMissingSpecial,
+	CodeTooManyRepeats:     ErrTooManyRepeats,
+	CodeForbiddenSubstring: ErrForbiddenSubstring,
+	CodeTooGuessable:       ErrTooGuessable,
+	CodeBreached:           ErrBreachedPassword,
+	CodeReused:             ErrReusedPassword,
+}
+
+// Violation is a single violated rule with the configured threshold and the password's actual value
+type Violation struct {
+	Code      ViolationCode
+	Threshold int
+	Actual    int
+}
+
+// Error returns the violation's message in English
+func (v Violation) Error() string {
+	return DefaultTranslator.Translate(v)
+}
+
+// Unwrap returns the rule's sentinel error, such as ErrTooShort
+func (v Violation) Unwrap() error {
+	return violationSentinels[v.Code]
+}
+
+// PolicyError lists every rule a password violated
+type PolicyError struct {
+	Violations []Violation
+}
+
+// Error joins the English messages of all violations
+func (e *PolicyError) Error() string {
+	return strings.Join(e.Messages(DefaultTranslator), "; ")
+}
+
+// Unwrap returns the violations, so errors.Is matches each rule's sentinel and
+// errors.As finds the first Violation
+func (e *PolicyError) Unwrap() []error {
+	errs := make([]error, len(e.Violations))
+	for i, v := range e.Violations {
+		errs[i] = v
+	}
+	return errs
+}
+
+// Has reports whether the rule identified by code was violated
+func (e *PolicyError) Has(code ViolationCode) bool {
+	for _, v := range e.Violations {
+		if v.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Messages renders every violation with t
+func (e *PolicyError) Messages(t Translator) []string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = t.Translate(v)
+	}
+	return messages
+}
+
+// Translator renders a violation as a human-readable message, for example in the user's language
+type Translator interface {
+	Translate(v Violation) string
+}
+
+// TranslatorFunc adapts a function to the Translator interface
+type TranslatorFunc func(v Violation) string
+
+func (f TranslatorFunc) Translate(v Violation) string {
+	return f(v)
+}
+
+// DefaultTranslator renders violations in English
+var DefaultTranslator Translator = TranslatorFunc(englishMessage)
+
+func englishMessage(v Violation) string {
+	switch v.Code {
+	case CodeTooShort:
+		return fmt.Sprintf("password must be at least %d characters long", v.Threshold)
+	case CodeTooLong:
+		return fmt.Sprintf("password must not exceed %d characters", v.Threshold)
+	case CodeMissingUppercase:
+		return countMessage(v.Threshold, "uppercase letter", "uppercase letters")
+	case CodeMissingLowercase:
+		return countMessage(v.Threshold, "lowercase letter", "lowercase letters")
+	case CodeMissingNumber:
+		return countMessage(v.Threshold, "number", "numbers")
+	case CodeMissingSpecial:
+		return countMessage(v.Threshold, "special character", "special characters")
+	case CodeTooManyRepeats:
+		return fmt.Sprintf("password must not repeat a character more than %d times in a row", v.Threshold)
+	case CodeForbiddenSubstring:
+		return "password must not contain your name, email address or other forbidden words"
+	case CodeTooGuessable:
+		return fmt.Sprintf("password is too guessable: strength score %d is below the required %d", v.Actual, v.Threshold)
+	case CodeBreached:
+		return ErrBreachedPassword.Error()
+	case CodeReused:
+		return "password must not match a recently used password"
+	}
+	return fmt.Sprintf("password violates rule %s", v.Code)
+}
+
+func countMessage(count int, singular, plural string) string {
+	if count <= 1 {
+		return "password must contain at least one " + singular
+	}
+	return fmt.Sprintf("password must contain at least %d %s", count, plural)
+}
@@ -0,0 +1,84 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestVerifyReportsAllViolations(t *testing.T) {
+	i := is.New(t)
+	err := Verify("abc")
+
+	var pe *PolicyError
+	i.True(errors.As(err, &pe))
+	i.Equal(pe.Violations, []Violation{
+		{Code: CodeTooShort, Threshold: 8, Actual: 3},
+		{Code: CodeMissingUppercase, Threshold: 1},
+		{Code: CodeMissingNumber, Threshold: 1},
+		{Code: CodeMissingSpecial, Threshold: 1},
+	})
+	i.True(pe.Has(CodeTooShort))
+	i.True(!pe.Has(CodeMissingLowercase))
+}
+
+func TestPolicyErrorSentinels(t *testing.T) {
+	i := is.New(t)
+	err := Verify(strings.Repeat("a", 129), VerifyWithBlocklistOption(NewPasswordSet(strings.Repeat("a", 129))))
+
+	i.True(errors.Is(err, ErrTooLong))
+	i.True(errors.Is(err, ErrMissingUppercase))
+	i.True(errors.Is(err, ErrBreachedPassword))
+	i.True(!errors.Is(err, ErrTooShort))
+	i.True(!errors.Is(err, ErrMissingLowercase))
+
+	var v Violation
+	i.True(errors.As(err, &v))
+	i.Equal(v, Violation{Code: CodeTooLong, Threshold: 128, Actual: 129})
+}
+
+func TestPolicyErrorMessages(t *testing.T) {
+	i := is.New(t)
+	err := Verify("Test1", VerifyWithoutSpecialOption())
+	i.Equal(err.Error(), "password must be at least 8 characters long")
+
+	err = Verify("test", VerifyWithMinLengthOption(4), VerifyWithoutSpecialOption())
+	i.Equal(err.Error(), "password must contain at least one uppercase letter; password must contain at least one number")
+}
+
+func TestPolicyErrorTranslator(t *testing.T) {
+	i := is.New(t)
+	german := TranslatorFunc(func(v Violation) string {
+		switch v.Code {
+		case CodeTooShort:
+			return "Passwort zu kurz"
+		case CodeMissingNumber:
+			return "Passwort braucht eine Ziffer"
+		}
+		return DefaultTranslator.Translate(v)
+	})
+
+	err := Verify("Test!", VerifyWithoutSpecialOption())
+	var pe *PolicyError
+	i.True(errors.As(err, &pe))
+	i.Equal(pe.Messages(german), []string{"Passwort zu kurz", "Passwort braucht eine Ziffer"})
+}
+
+func TestVerifyTooGuessableViolation(t *testing.T) {
+	i := is.New(t)
+	err := Verify("Password1!", VerifyWithMinScoreOption(3))
+	i.True(errors.Is(err, ErrTooGuessable))
+
+	var v Violation
+	i.True(errors.As(err, &v))
+	i.Equal(v.Threshold, 3)
+	i.True(v.Actual < 3)
+}
+
+func TestVerifyNoViolationsReturnsNil(t *testing.T) {
+	i := is.New(t)
+	err := Verify("Test123!@#")
+	i.True(err == nil)
+}